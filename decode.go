@@ -0,0 +1,205 @@
+package jsonreader
+
+import (
+    "errors"
+    "io"
+
+    "encoding/json"
+
+    "github.com/dsoprea/go-logging"
+)
+
+var (
+    // ErrAlreadyDecoded indicates that Decode was called more than once for
+    // the same value.
+    ErrAlreadyDecoded = errors.New("value already decoded")
+
+    // ErrParsingStarted indicates that DecodeAt was called after tokens were
+    // already read.
+    ErrParsingStarted = errors.New("parsing already started")
+)
+
+// Decoder decodes a single value that was matched by DecodeAt.
+type Decoder interface {
+    // Decode unmarshals the value into v like json.Decoder.Decode (honoring
+    // `json` struct tags). The bytes are read directly from the input. It may
+    // only be called once. If it's not called, the value is skipped.
+    Decode(v interface{}) error
+
+    // Path returns the path of the value.
+    Path() Path
+}
+
+// valueDecoder is the Decoder that is given to the DecodeAt callback.
+type valueDecoder struct {
+    p *Parser
+    location tokenLocation
+    decoded bool
+}
+
+func (vd *valueDecoder) Decode(v interface{}) (err error) {
+    defer func() {
+        if state := recover(); state != nil {
+            err = unwrapError(state)
+        }
+    }()
+
+    if vd.decoded == true {
+        log.Panic(ErrAlreadyDecoded)
+    }
+
+    // The decoder consumes the value even if it can't be unmarshaled into
+    // the given type.
+    vd.decoded = true
+
+    err = vd.p.source.Decode(v)
+    if err != nil {
+        // A value that can't be unmarshaled into the given type is still
+        // consumed, but the decoder can't continue after a syntax or read
+        // error.
+        if isStreamError(err) == true {
+            err = vd.p.sourceError(err)
+            vd.p.err = err
+        } else {
+            vd.p.markToken()
+        }
+
+        log.Panic(err)
+    }
+
+    vd.p.markToken()
+
+    return nil
+}
+
+// isStreamError returns true if the error from tokenSource.Decode means that
+// the input can't be read any further.
+func isStreamError(err error) bool {
+    var se *json.SyntaxError
+    if errors.As(err, &se) == true {
+        return true
+    }
+
+    var scanErr *scanError
+    if errors.As(err, &scanErr) == true {
+        return true
+    }
+
+    return err == io.EOF || err == io.ErrUnexpectedEOF
+}
+
+func (vd *valueDecoder) Path() Path {
+    return vd.location.Path()
+}
+
+// DecodeAt calls the callback for every value whose path is matched exactly
+// by the selector, and the callback decodes the value into whatever type it
+// needs. Everything else is skipped without being tokenized. This consumes the
+// rest of the input and must be called instead of Next() (not after it). If
+// the callback returns an error, parsing stops and the error is returned.
+func (p *Parser) DecodeAt(selector *Selector, callback func(dec Decoder) error) (err error) {
+    defer func() {
+        if state := recover(); state != nil {
+            err = unwrapError(state)
+        }
+    }()
+
+    err = p.startDecoding(selector, callback)
+    log.PanicIf(err)
+
+    defer func() {
+        p.decodeCallback = nil
+    }()
+
+    for {
+        _, err := p.Next()
+        if err == io.EOF {
+            break
+        }
+
+        log.PanicIf(err)
+    }
+
+    return nil
+}
+
+// startDecoding sets up the parser to call the callback for the values matched
+// by the selector as Next() is called.
+func (p *Parser) startDecoding(selector *Selector, callback func(dec Decoder) error) (err error) {
+    defer func() {
+        if state := recover(); state != nil {
+            err = unwrapError(state)
+        }
+    }()
+
+    root := &p.frames[0]
+    if len(p.frames) > 1 || root.i > 0 || p.pendingIndex < len(p.pending) || p.inDocument == true {
+        log.Panic(ErrParsingStarted)
+    }
+
+    // We only care about the values that are matched, so let everything else
+    // be skipped.
+    p.selectors = []*Selector{selector}
+    root.selectorStates, root.selected = initialSelectorStates(p.selectors)
+
+    p.decodeCallback = callback
+
+    return nil
+}
+
+// decodeSelected calls the DecodeAt callback if the next value is matched.
+func (p *Parser) decodeSelected() (decoded bool, err error) {
+    defer func() {
+        if state := recover(); state != nil {
+            err = unwrapError(state)
+        }
+    }()
+
+    current := p.currentFrame()
+
+    switch current.dc.Delimiter() {
+    case '{':
+        // We're only able to decode once we have the key.
+        if current.i % 2 == 0 {
+            return false, nil
+        }
+    case '[':
+        if p.source.More() == false {
+            return false, nil
+        }
+    default:
+        // We're at the top level. The root is only matched by "$".
+        if current.selected == false || p.source.More() == false {
+            return false, nil
+        }
+    }
+
+    location := tokenLocation{
+        dc: current.dc,
+    }
+
+    location.element, location.hasElement = current.cursor()
+
+    if location.hasElement == true && matchSelectors(current.selectorStates, location.element) == false {
+        return false, nil
+    }
+
+    vd := &valueDecoder{
+        p: p,
+        location: location,
+    }
+
+    err = p.decodeCallback(vd)
+    log.PanicIf(err)
+
+    if vd.decoded == false {
+        err := p.source.Skip()
+        if err != nil {
+            log.Panic(p.sourceError(err))
+        }
+    }
+
+    current.i++
+
+    return true, nil
+}
@@ -0,0 +1,176 @@
+package jsonreader
+
+import (
+    "testing"
+    "os"
+    "path"
+    "strings"
+    "errors"
+
+    "github.com/dsoprea/go-logging"
+)
+
+type testLocation struct {
+    TimestampMs string `json:"timestampMs"`
+    LatitudeE7 int64 `json:"latitudeE7"`
+    LongitudeE7 int64 `json:"longitudeE7"`
+    Accuracy int `json:"accuracy"`
+}
+
+func TestParser_DecodeAt(t *testing.T) {
+    filepath := path.Join(testingAssetsPath, "data1.json")
+
+    f, err := os.Open(filepath)
+    log.PanicIf(err)
+
+    defer f.Close()
+
+    p := NewParser(f)
+
+    locations := make([]testLocation, 0)
+    paths := make([]string, 0)
+
+    err = p.DecodeAt(mustParseSelector("$.locations[*]"), func(dec Decoder) error {
+        var location testLocation
+
+        err := dec.Decode(&location)
+        log.PanicIf(err)
+
+        locations = append(locations, location)
+        paths = append(paths, dec.Path().String())
+
+        return nil
+    })
+
+    log.PanicIf(err)
+
+    if len(locations) != 12 {
+        t.Fatalf("Location count not correct: (%d)", len(locations))
+    }
+
+    expected := testLocation{
+        TimestampMs: "1517218739237",
+        LatitudeE7: 265620925,
+        LongitudeE7: -801004559,
+        Accuracy: 600,
+    }
+
+    if locations[0] != expected {
+        t.Fatalf("Location not correct: %v", locations[0])
+    } else if paths[11] != "$.locations[11]" {
+        t.Fatalf("Path not correct: [%s]", paths[11])
+    }
+}
+
+func TestParser_DecodeAt_Skip(t *testing.T) {
+    r := strings.NewReader(`{"aa": [{"bb": 1}, {"bb": 2}, {"bb": 3}], "cc": {"bb": 4}}`)
+
+    p := NewParser(r)
+
+    values := make([]int, 0)
+    err := p.DecodeAt(mustParseSelector("$..bb"), func(dec Decoder) error {
+        // Leave the second one to be skipped.
+        if dec.Path().String() == "$.aa[1].bb" {
+            return nil
+        }
+
+        var value int
+
+        err := dec.Decode(&value)
+        log.PanicIf(err)
+
+        values = append(values, value)
+
+        return nil
+    })
+
+    log.PanicIf(err)
+
+    if len(values) != 3 || values[0] != 1 || values[1] != 3 || values[2] != 4 {
+        t.Fatalf("Values not correct: %v", values)
+    }
+}
+
+func TestParser_DecodeAt_Root(t *testing.T) {
+    r := strings.NewReader(`{"aa": 1}`)
+
+    p := NewParser(r)
+
+    var value map[string]int
+    err := p.DecodeAt(mustParseSelector("$"), func(dec Decoder) error {
+        return dec.Decode(&value)
+    })
+
+    log.PanicIf(err)
+
+    if value["aa"] != 1 {
+        t.Fatalf("Value not correct: %v", value)
+    }
+}
+
+func TestParser_DecodeAt_TypeError(t *testing.T) {
+    r := strings.NewReader(`["aa", 1, 2]`)
+
+    p := NewParser(r)
+
+    values := make([]int, 0)
+    errorCount := 0
+
+    err := p.DecodeAt(mustParseSelector("$[*]"), func(dec Decoder) error {
+        var value int
+
+        if err := dec.Decode(&value); err != nil {
+            errorCount++
+            return nil
+        }
+
+        values = append(values, value)
+
+        return nil
+    })
+
+    log.PanicIf(err)
+
+    if errorCount != 1 {
+        t.Fatalf("Error count not correct: (%d)", errorCount)
+    } else if len(values) != 2 || values[0] != 1 || values[1] != 2 {
+        t.Fatalf("Values not correct: %v", values)
+    }
+}
+
+func TestParser_DecodeAt_CallbackError(t *testing.T) {
+    r := strings.NewReader(`[1, 2, 3]`)
+
+    p := NewParser(r)
+
+    errStop := errors.New("stop")
+
+    count := 0
+    err := p.DecodeAt(mustParseSelector("$[*]"), func(dec Decoder) error {
+        count++
+        return errStop
+    })
+
+    if errors.Is(err, errStop) == false {
+        t.Fatalf("Expected callback error: %v", err)
+    } else if count != 1 {
+        t.Fatalf("Callback count not correct: (%d)", count)
+    }
+}
+
+func TestParser_DecodeAt_Started(t *testing.T) {
+    r := strings.NewReader(`[1, 2, 3]`)
+
+    p := NewParser(r)
+
+    _, err := p.Next()
+    log.PanicIf(err)
+
+    err = p.DecodeAt(mustParseSelector("$[*]"), func(dec Decoder) error {
+        return nil
+    })
+
+    if errors.Is(err, ErrParsingStarted) == false {
+        t.Fatalf("Expected parsing-started error: %v", err)
+    }
+}
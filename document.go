@@ -0,0 +1,296 @@
+package jsonreader
+
+import (
+    "bufio"
+    "bytes"
+    "io"
+    "strconv"
+
+    "github.com/dsoprea/go-logging"
+)
+
+// DocumentMode determines how many documents (top-level values) the input has
+// and how they are separated.
+type DocumentMode int
+
+const (
+    // DocumentModeSingle reads the input as one document. This is the
+    // default.
+    DocumentModeSingle DocumentMode = iota
+
+    // DocumentModeLines reads the input as newline-delimited JSON (NDJSON or
+    // JSON Lines): every non-blank line is a separate document. The tokens of
+    // each document are bracketed by DocumentStart and DocumentEnd.
+    DocumentModeLines
+
+    // DocumentModeConcatenated reads the input as any number of documents
+    // that simply follow each other, optionally separated by whitespace
+    // (e.g. a stream of objects from an API). Documents may span lines.
+    DocumentModeConcatenated
+
+    // DocumentModeSequence reads the input as a JSON text sequence (RFC
+    // 7464), where every document is preceded by a record separator (0x1E).
+    DocumentModeSequence
+)
+
+const (
+    // recordSeparator precedes every document in a JSON text sequence.
+    recordSeparator = 0x1e
+)
+
+// String returns a descriptive name for the mode.
+func (dm DocumentMode) String() string {
+    switch dm {
+    case DocumentModeSingle:
+        return "Single"
+    case DocumentModeLines:
+        return "Lines"
+    case DocumentModeConcatenated:
+        return "Concatenated"
+    case DocumentModeSequence:
+        return "Sequence"
+    }
+
+    return "DocumentMode(" + strconv.Itoa(int(dm)) + ")"
+}
+
+// DocumentStart is produced before the tokens of every document when the input
+// has more than one (see DocumentMode). Document boundaries are produced even
+// if nothing in the document is selected.
+type DocumentStart struct {
+    // Line is the line that the document starts on.
+    Line int
+}
+
+// DocumentEnd is produced after the tokens of every document when the input
+// has more than one.
+type DocumentEnd struct {
+    // Line is the line that the document ends on.
+    Line int
+}
+
+// WithDocumentMode determines how many documents the input has. The default is
+// DocumentModeSingle.
+func WithDocumentMode(mode DocumentMode) ParserOption {
+    return func(p *Parser) {
+        p.documentMode = mode
+    }
+}
+
+// recordReader splits the input into records that are separated by a
+// delimiter (e.g. lines). Only the current record is held in memory.
+type recordReader struct {
+    r *bufio.Reader
+    delimiter byte
+
+    // line and offset are where the next record starts and lineStart is the
+    // offset of the first byte of that line.
+    line int
+    offset int64
+    lineStart int64
+
+    buf []byte
+}
+
+func newRecordReader(r io.Reader, delimiter byte) *recordReader {
+    return &recordReader{
+        r: bufio.NewReader(r),
+        delimiter: delimiter,
+        line: 1,
+        buf: make([]byte, 0),
+    }
+}
+
+// next returns the next record that isn't blank along with where it starts.
+// The delimiter isn't included unless it's a newline. The record is only valid
+// until the next call. It returns io.EOF when there are no more records.
+func (rr *recordReader) next() (record []byte, start Position, err error) {
+    for {
+        rr.buf = rr.buf[:0]
+
+        for {
+            chunk, err := rr.r.ReadSlice(rr.delimiter)
+            rr.buf = append(rr.buf, chunk...)
+
+            if err == bufio.ErrBufferFull {
+                continue
+            } else if err == io.EOF && len(rr.buf) > 0 {
+                // The last record doesn't have to be terminated.
+                break
+            } else if err != nil {
+                return nil, Position{}, err
+            }
+
+            break
+        }
+
+        start = Position{
+            Offset: rr.offset,
+            Line: rr.line,
+            Column: int(rr.offset - rr.lineStart) + 1,
+        }
+
+        if i := bytes.LastIndexByte(rr.buf, '\n'); i != -1 {
+            rr.line += bytes.Count(rr.buf, []byte{'\n'})
+            rr.lineStart = rr.offset + int64(i) + 1
+        }
+
+        rr.offset += int64(len(rr.buf))
+
+        record = rr.buf
+        if rr.delimiter != '\n' && len(record) > 0 && record[len(record) - 1] == rr.delimiter {
+            record = record[:len(record) - 1]
+        }
+
+        if len(bytes.TrimSpace(record)) == 0 {
+            continue
+        }
+
+        return record, start, nil
+    }
+}
+
+// emitBoundary queues a document boundary. These aren't subject to the
+// selectors.
+func (p *Parser) emitBoundary(token interface{}) {
+    p.pending = append(p.pending, Token{
+        kind: kindOf(token),
+        value: token,
+        location: tokenLocation{
+            span: p.span,
+        },
+    })
+}
+
+// stepDocument starts the next document if we're between documents and ends
+// the current one once its value is complete. It returns true if it handled the
+// step.
+func (p *Parser) stepDocument() (handled bool, err error) {
+    defer func() {
+        if state := recover(); state != nil {
+            err = unwrapError(state)
+        }
+    }()
+
+    if p.records == nil {
+        return p.stepConcatenated()
+    }
+
+    if p.inDocument == false {
+        record, start, err := p.records.next()
+        if err == io.EOF {
+            p.done = true
+            return true, nil
+        }
+
+        log.PanicIf(err)
+
+        if p.recordErrors == true {
+            if re := checkRecord(record, start, p.numberMode); re != nil {
+                p.recordErrorCount++
+
+                p.span = Span{
+                    Start: start,
+                    End: positionInRecord(record, start, int64(len(re.Raw))),
+                }
+
+                p.emitBoundary(*re)
+                return true, nil
+            }
+        }
+
+        p.tracker = newLineTrackerAt(bytes.NewReader(record), start)
+        p.source = p.newSource(p.tracker)
+        p.resetRoot()
+
+        p.inDocument = true
+
+        p.span = Span{Start: start, End: start}
+        p.emitBoundary(DocumentStart{Line: start.Line})
+
+        return true, nil
+    }
+
+    root := &p.frames[0]
+    if len(p.frames) > 1 || root.i == 0 {
+        return false, nil
+    }
+
+    // The value is complete, so the only thing left should be the end of the
+    // document.
+    t, err := p.source.Token()
+    if err == io.EOF {
+        p.endDocument()
+        return true, nil
+    } else if err != nil {
+        log.Panic(p.sourceError(err))
+    }
+
+    p.markToken()
+
+    se := &SyntaxError{
+        Position: p.span.Start,
+        Path: Path{},
+        Expected: "end of document",
+        Actual: describeToken(t),
+        Err: ErrTrailingData,
+    }
+
+    log.Panic(se)
+    return false, nil
+}
+
+// stepConcatenated is stepDocument for documents that follow each other in
+// the same stream.
+func (p *Parser) stepConcatenated() (handled bool, err error) {
+    defer func() {
+        if state := recover(); state != nil {
+            err = unwrapError(state)
+        }
+    }()
+
+    if p.inDocument == false {
+        if p.source.More() == false {
+            // This is either the end of the input or an error.
+            t, err := p.source.Token()
+            if err == io.EOF {
+                p.done = true
+                return true, nil
+            } else if err != nil {
+                log.Panic(p.sourceError(err))
+            }
+
+            log.Panicf("unexpected token between documents: [%v]", t)
+        }
+
+        p.resetRoot()
+        p.inDocument = true
+
+        start := p.tracker.position(p.source.NextOffset())
+
+        p.span = Span{Start: start, End: start}
+        p.emitBoundary(DocumentStart{Line: start.Line})
+
+        return true, nil
+    }
+
+    root := &p.frames[0]
+    if len(p.frames) > 1 || root.i == 0 {
+        return false, nil
+    }
+
+    // The value is complete. We don't need to read anything else to know
+    // that.
+    p.endDocument()
+
+    return true, nil
+}
+
+// endDocument produces the boundary after the last token of the current
+// document.
+func (p *Parser) endDocument() {
+    p.inDocument = false
+
+    p.span = Span{Start: p.span.End, End: p.span.End}
+    p.emitBoundary(DocumentEnd{Line: p.span.End.Line})
+}
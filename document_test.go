@@ -0,0 +1,317 @@
+package jsonreader
+
+import (
+    "testing"
+    "strings"
+    "reflect"
+    "errors"
+    "fmt"
+
+    "github.com/dsoprea/go-logging"
+)
+
+func TestParser_DocumentModeLines(t *testing.T) {
+    data := "{\"aa\": 1}\n\n[true, \"bb\"]\r\n  \"cc\"  \n{\"dd\": {\"ee\": null}}"
+
+    expected := []string{
+        "/DOCUMENTSTART=1",
+        "/OBJECTOPEN",
+        ":aa",
+        "[aa] F 1.000000",
+        "/OBJECTCLOSE",
+        "@aa:1",
+        "/DOCUMENTEND=1",
+        "/DOCUMENTSTART=3",
+        "/LISTOPEN",
+        "#BOOL=true",
+        "#STRING=bb",
+        "/LISTCLOSE",
+        "/DOCUMENTEND=3",
+        "/DOCUMENTSTART=4",
+        "#STRING=cc",
+        "/DOCUMENTEND=4",
+        "/DOCUMENTSTART=5",
+        "/OBJECTOPEN",
+        ":dd",
+        "/OBJECTOPEN",
+        ":ee",
+        "[ee] N",
+        "/OBJECTCLOSE",
+        "@ee:<nil>",
+        "/OBJECTCLOSE",
+        "@",
+        "/DOCUMENTEND=5",
+    }
+
+    for _, native := range []bool{false, true} {
+        options := []ParserOption{WithDocumentMode(DocumentModeLines)}
+        if native == false {
+            options = append(options, WithStandardDecoder())
+        }
+
+        p := NewParser(&byteReader{data: []byte(data)}, options...)
+
+        ts, err := flattenNext(p)
+        log.PanicIf(err)
+
+        if reflect.DeepEqual(ts, expected) == false {
+            t.Fatalf("Tokens not correct (native=%v):\nACTUAL: %v\nEXPECTED: %v", native, ts, expected)
+        }
+    }
+}
+
+func TestParser_DocumentModeLines_Position(t *testing.T) {
+    data := "{\"aa\": 1}\n\n[1,\n"
+
+    p := NewParser(strings.NewReader(data), WithDocumentMode(DocumentModeLines))
+
+    spans := make([]string, 0)
+    for {
+        token, err := p.Next()
+        if err != nil {
+            var se *SyntaxError
+            if errors.As(err, &se) == false {
+                t.Fatalf("Expected a SyntaxError: %v", err)
+            } else if se.Position.Line != 3 || se.Position.Column != 4 || se.Position.Offset != 14 {
+                t.Fatalf("Position not correct: %v", se.Position)
+            }
+
+            break
+        }
+
+        span := p.Span()
+        spans = append(spans, fmt.Sprintf("%s %d:%d", flattenToken(token), span.Start.Line, span.Start.Column))
+    }
+
+    expected := []string{
+        "/DOCUMENTSTART=1 1:1",
+        "/OBJECTOPEN 1:1",
+        ":aa 1:2",
+        "[aa] F 1.000000 1:8",
+        "/OBJECTCLOSE 1:9",
+        "@aa:1 1:1",
+        "/DOCUMENTEND=1 1:10",
+        "/DOCUMENTSTART=3 3:1",
+        "/LISTOPEN 3:1",
+        "#FLOAT64=1.000000 3:2",
+    }
+
+    if reflect.DeepEqual(spans, expected) == false {
+        t.Fatalf("Spans not correct:\nACTUAL: %v\nEXPECTED: %v", spans, expected)
+    }
+}
+
+func TestParser_DocumentModeLines_TrailingData(t *testing.T) {
+    for _, native := range []bool{false, true} {
+        options := []ParserOption{WithDocumentMode(DocumentModeLines)}
+        if native == false {
+            options = append(options, WithStandardDecoder())
+        }
+
+        p := NewParser(strings.NewReader("1\n2 3\n"), options...)
+
+        err := nextError(p)
+        if errors.Is(err, ErrTrailingData) == false {
+            t.Fatalf("Expected trailing-data error (native=%v): %v", native, err)
+        }
+    }
+}
+
+func TestParser_DocumentModeLines_Selectors(t *testing.T) {
+    data := "{\"aa\": {\"bb\": 1}, \"cc\": 2}\n{\"aa\": {\"bb\": 3}}\n"
+
+    selector := mustParseSelector("$.aa")
+    p := NewParser(strings.NewReader(data), WithDocumentMode(DocumentModeLines), WithSelectors(selector))
+
+    ts, err := flattenNext(p)
+    log.PanicIf(err)
+
+    expected := []string{
+        "/DOCUMENTSTART=1",
+        ":aa",
+        "/OBJECTOPEN",
+        ":bb",
+        "[bb] F 1.000000",
+        "/OBJECTCLOSE",
+        "@bb:1",
+        "/DOCUMENTEND=1",
+        "/DOCUMENTSTART=2",
+        ":aa",
+        "/OBJECTOPEN",
+        ":bb",
+        "[bb] F 3.000000",
+        "/OBJECTCLOSE",
+        "@bb:3",
+        "/DOCUMENTEND=2",
+    }
+
+    if reflect.DeepEqual(ts, expected) == false {
+        t.Fatalf("Tokens not correct:\nACTUAL: %v\nEXPECTED: %v", ts, expected)
+    }
+}
+
+func TestParser_DocumentModeLines_DecodeAt(t *testing.T) {
+    data := "{\"timestampMs\": \"1\", \"latitudeE7\": 2}\n{\"timestampMs\": \"3\", \"latitudeE7\": 4}\n"
+
+    p := NewParser(strings.NewReader(data), WithDocumentMode(DocumentModeLines))
+
+    locations := make([]testLocation, 0)
+    err := p.DecodeAt(mustParseSelector("$"), func(dec Decoder) error {
+        var location testLocation
+        if err := dec.Decode(&location); err != nil {
+            return err
+        }
+
+        locations = append(locations, location)
+        return nil
+    })
+
+    log.PanicIf(err)
+
+    if len(locations) != 2 || locations[1].TimestampMs != "3" || locations[1].LatitudeE7 != 4 {
+        t.Fatalf("Locations not correct: %v", locations)
+    }
+}
+
+func TestDocumentMode_String(t *testing.T) {
+    if DocumentModeLines.String() != "Lines" {
+        t.Fatalf("String not correct: [%s]", DocumentModeLines.String())
+    } else if DocumentMode(99).String() != "DocumentMode(99)" {
+        t.Fatalf("String not correct for unknown mode: [%s]", DocumentMode(99).String())
+    }
+}
+
+func TestParser_DocumentModeConcatenated(t *testing.T) {
+    data := "{\"aa\": 1}{\"bb\": [2]}\n  3 \"cc\"\n[\n]"
+
+    expected := []string{
+        "/DOCUMENTSTART=1",
+        "/OBJECTOPEN",
+        ":aa",
+        "[aa] F 1.000000",
+        "/OBJECTCLOSE",
+        "@aa:1",
+        "/DOCUMENTEND=1",
+        "/DOCUMENTSTART=1",
+        "/OBJECTOPEN",
+        ":bb",
+        "/LISTOPEN",
+        "#FLOAT64=2.000000",
+        "/LISTCLOSE",
+        "/OBJECTCLOSE",
+        "@",
+        "/DOCUMENTEND=1",
+        "/DOCUMENTSTART=2",
+        "#FLOAT64=3.000000",
+        "/DOCUMENTEND=2",
+        "/DOCUMENTSTART=2",
+        "#STRING=cc",
+        "/DOCUMENTEND=2",
+        "/DOCUMENTSTART=3",
+        "/LISTOPEN",
+        "/LISTCLOSE",
+        "/DOCUMENTEND=4",
+    }
+
+    for _, native := range []bool{false, true} {
+        options := []ParserOption{WithDocumentMode(DocumentModeConcatenated)}
+        if native == false {
+            options = append(options, WithStandardDecoder())
+        }
+
+        p := NewParser(&byteReader{data: []byte(data)}, options...)
+
+        ts, err := flattenNext(p)
+        log.PanicIf(err)
+
+        if reflect.DeepEqual(ts, expected) == false {
+            t.Fatalf("Tokens not correct (native=%v):\nACTUAL: %v\nEXPECTED: %v", native, ts, expected)
+        }
+    }
+}
+
+func TestParser_DocumentModeConcatenated_Streaming(t *testing.T) {
+    data := []byte(`{"aa": 1} {"bb": 2}`)
+    br := &byteReader{
+        data: data,
+    }
+
+    p := NewParser(br, WithDocumentMode(DocumentModeConcatenated))
+
+    // The end of the first document is known without reading the second.
+    for {
+        token, err := p.Next()
+        log.PanicIf(err)
+
+        if token.Kind() == KindDocumentEnd {
+            break
+        }
+    }
+
+    if br.reads >= len(data) {
+        t.Fatalf("All of the data was read.")
+    }
+}
+
+func TestParser_DocumentModeConcatenated_Error(t *testing.T) {
+    p := NewParser(strings.NewReader(`{"aa": 1} ]`), WithDocumentMode(DocumentModeConcatenated))
+
+    err := nextError(p)
+    if errors.Is(err, ErrSyntax) == false {
+        t.Fatalf("Expected syntax error: %v", err)
+    }
+}
+
+func TestParser_DocumentModeSequence(t *testing.T) {
+    data := "\x1e{\"aa\": 1}\n\x1e[1,\n2]\n\x1e\n\x1e\"cc\"\n"
+
+    expected := []string{
+        "/DOCUMENTSTART=1",
+        "/OBJECTOPEN",
+        ":aa",
+        "[aa] F 1.000000",
+        "/OBJECTCLOSE",
+        "@aa:1",
+        "/DOCUMENTEND=1",
+        "/DOCUMENTSTART=2",
+        "/LISTOPEN",
+        "#FLOAT64=1.000000",
+        "#FLOAT64=2.000000",
+        "/LISTCLOSE",
+        "/DOCUMENTEND=3",
+        "/DOCUMENTSTART=5",
+        "#STRING=cc",
+        "/DOCUMENTEND=5",
+    }
+
+    for _, native := range []bool{false, true} {
+        options := []ParserOption{WithDocumentMode(DocumentModeSequence)}
+        if native == false {
+            options = append(options, WithStandardDecoder())
+        }
+
+        p := NewParser(strings.NewReader(data), options...)
+
+        ts, err := flattenNext(p)
+        log.PanicIf(err)
+
+        if reflect.DeepEqual(ts, expected) == false {
+            t.Fatalf("Tokens not correct (native=%v):\nACTUAL: %v\nEXPECTED: %v", native, ts, expected)
+        }
+    }
+}
+
+func TestParser_DocumentModeSequence_Position(t *testing.T) {
+    data := "\x1e1\n\x1e[1,\n 2 3]\n"
+
+    p := NewParser(strings.NewReader(data), WithDocumentMode(DocumentModeSequence))
+
+    err := nextError(p)
+
+    var se *SyntaxError
+    if errors.As(err, &se) == false {
+        t.Fatalf("Expected a SyntaxError: %v", err)
+    } else if se.Position.Line != 3 || se.Position.Column != 4 || se.Position.Offset != 11 {
+        t.Fatalf("Position not correct: %v", se.Position)
+    }
+}
@@ -0,0 +1,96 @@
+package jsonreader
+
+import (
+    "errors"
+    "fmt"
+    "io"
+    "iter"
+)
+
+var (
+    // errStopElements is how Elements stops DecodeAt when the loop breaks.
+    errStopElements = errors.New("stop elements")
+)
+
+// ElementError is yielded by Elements when one element can't be decoded.
+// Iteration continues with the next element.
+type ElementError struct {
+    // Index is the index of the element in the list.
+    Index int
+
+    // Path is the path of the element.
+    Path Path
+
+    // Span is where the element is in the input.
+    Span Span
+
+    Err error
+}
+
+func (ee *ElementError) Error() string {
+    return fmt.Sprintf("element (%d) at [%s] (line %d, column %d) could not be decoded: %s", ee.Index, ee.Path, ee.Span.Start.Line, ee.Span.Start.Column, ee.Err.Error())
+}
+
+func (ee *ElementError) Unwrap() error {
+    return ee.Err
+}
+
+// Elements returns an iterator over the elements of the list at the given path
+// (e.g. "$" or "$.items"), each decoded into a T. Only one element is held in
+// memory at a time, regardless of the length of the list. If an element can't
+// be decoded, an *ElementError is yielded and iteration continues. Any other
+// error is yielded once and iteration stops. Breaking out of the loop stops
+// reading from the reader.
+func Elements[T any](r io.Reader, path string, options ...ParserOption) iter.Seq2[T, error] {
+    return func(yield func(T, error) bool) {
+        var zero T
+
+        selector, err := ParseSelector(path + "[*]")
+        if err != nil {
+            yield(zero, err)
+            return
+        }
+
+        p := NewParser(r, options...)
+
+        err = p.DecodeAt(selector, func(dec Decoder) error {
+            vd := dec.(*valueDecoder)
+
+            // The wildcard would also match the members of an object.
+            if vd.location.element.IsIndex == false {
+                return nil
+            }
+
+            var value T
+            if err := dec.Decode(&value); err != nil {
+                // Don't attribute a syntax or read error to the element.
+                if vd.p.err != nil {
+                    return err
+                }
+
+                ee := &ElementError{
+                    Index: vd.location.element.Index,
+                    Path: dec.Path(),
+                    Span: vd.p.span,
+                    Err: err,
+                }
+
+                if yield(zero, ee) == false {
+                    return errStopElements
+                }
+
+                return nil
+            }
+
+            if yield(value, nil) == false {
+                return errStopElements
+            }
+
+            return nil
+        })
+
+        if err != nil && errors.Is(err, errStopElements) == false {
+            yield(zero, err)
+        }
+    }
+}
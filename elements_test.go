@@ -0,0 +1,121 @@
+package jsonreader
+
+import (
+    "testing"
+    "os"
+    "path"
+    "strings"
+    "errors"
+
+    "github.com/dsoprea/go-logging"
+)
+
+func TestElements(t *testing.T) {
+    filepath := path.Join(testingAssetsPath, "data1.json")
+
+    f, err := os.Open(filepath)
+    log.PanicIf(err)
+
+    defer f.Close()
+
+    locations := make([]testLocation, 0)
+    for location, err := range Elements[testLocation](f, "$.locations") {
+        log.PanicIf(err)
+
+        locations = append(locations, location)
+    }
+
+    if len(locations) != 12 {
+        t.Fatalf("Location count not correct: (%d)", len(locations))
+    } else if locations[11].TimestampMs != "1376344184115" {
+        t.Fatalf("Last location not correct: %v", locations[11])
+    }
+}
+
+func TestElements_Root(t *testing.T) {
+    r := strings.NewReader(`[1, 2, 3]`)
+
+    values := make([]int, 0)
+    for value, err := range Elements[int](r, "$") {
+        log.PanicIf(err)
+
+        values = append(values, value)
+    }
+
+    if len(values) != 3 || values[2] != 3 {
+        t.Fatalf("Values not correct: %v", values)
+    }
+}
+
+func TestElements_ElementError(t *testing.T) {
+    r := strings.NewReader(`{"items": [1, "two", 3], "other": {"x": 4}}`)
+
+    values := make([]int, 0)
+    var ee *ElementError
+
+    for value, err := range Elements[int](r, "$.items") {
+        if err != nil {
+            if errors.As(err, &ee) == false {
+                t.Fatalf("Expected element error: %v", err)
+            }
+
+            continue
+        }
+
+        values = append(values, value)
+    }
+
+    if len(values) != 2 || values[0] != 1 || values[1] != 3 {
+        t.Fatalf("Values not correct: %v", values)
+    } else if ee == nil || ee.Index != 1 || ee.Path.String() != "$.items[1]" {
+        t.Fatalf("Element error not correct: %v", ee)
+    } else if ee.Span.Start.Offset != 14 || ee.Span.End.Offset != 19 || ee.Span.Start.Column != 15 {
+        t.Fatalf("Element error span not correct: %v", ee.Span)
+    }
+}
+
+func TestElements_Break(t *testing.T) {
+    data := []byte(`[1, 2, 3, 4, 5, 6, 7, 8]`)
+    br := &byteReader{
+        data: data,
+    }
+
+    for _, err := range Elements[int](br, "$") {
+        log.PanicIf(err)
+        break
+    }
+
+    if br.reads >= len(data) {
+        t.Fatalf("All of the data was read.")
+    }
+}
+
+func TestElements_SyntaxError(t *testing.T) {
+    r := strings.NewReader(`[1, 2, }`)
+
+    var lastErr error
+    count := 0
+    for _, err := range Elements[int](r, "$") {
+        if err != nil {
+            lastErr = err
+        } else {
+            count++
+        }
+    }
+
+    if lastErr == nil {
+        t.Fatalf("Expected syntax error.")
+    } else if count != 2 {
+        t.Fatalf("Value count not correct: (%d)", count)
+    }
+}
+
+func TestElements_InvalidPath(t *testing.T) {
+    r := strings.NewReader(`[1]`)
+
+    for _, err := range Elements[int](r, "items") {
+        if err == nil {
+            t.Fatalf("Expected error for invalid path.")
+        }
+    }
+}
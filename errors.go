@@ -0,0 +1,191 @@
+package jsonreader
+
+import (
+    "errors"
+    "fmt"
+    "strconv"
+    "strings"
+
+    "encoding/json"
+
+    goerrors "github.com/go-errors/errors"
+)
+
+var (
+    // ErrSyntax is matched (with errors.Is) by every *SyntaxError.
+    ErrSyntax = errors.New("invalid JSON")
+
+    // ErrUnbalancedDelimiters indicates that an object or list was closed by
+    // the wrong delimiter or that there was nothing to close.
+    ErrUnbalancedDelimiters = errors.New("unbalanced delimiters")
+
+    // ErrNotInContainer indicates that Skip was called at the top level.
+    ErrNotInContainer = errors.New("not inside an object or list")
+
+    // ErrTrailingData indicates that there was more than one value in a
+    // document.
+    ErrTrailingData = errors.New("data after the end of the document")
+
+    // ErrInvalidSelector indicates that a selector expression couldn't be
+    // parsed.
+    ErrInvalidSelector = errors.New("invalid selector")
+)
+
+// SyntaxError is an error in the input along with where it was found. Use
+// errors.As to get it from any error returned by the parser.
+type SyntaxError struct {
+    Position Position
+
+    // Path is the path of the value that was being read.
+    Path Path
+
+    // Expected describes what was expected at that position and Actual is
+    // what was found instead (e.g. "',' or ']'" and "'3'"). Either may be
+    // empty if it isn't known.
+    Expected string
+    Actual string
+
+    // Err is the underlying error. This is io.ErrUnexpectedEOF if the input
+    // ended in the middle of a value and ErrUnbalancedDelimiters if an object
+    // or list was closed by the wrong delimiter.
+    Err error
+}
+
+func (se *SyntaxError) Error() string {
+    message := fmt.Sprintf("line %d, column %d: %s", se.Position.Line, se.Position.Column, se.Err.Error())
+
+    if se.Expected != "" && se.Actual != "" {
+        message += fmt.Sprintf(" (expected %s, found %s)", se.Expected, se.Actual)
+    } else if se.Expected != "" {
+        message += fmt.Sprintf(" (expected %s)", se.Expected)
+    }
+
+    if len(se.Path) > 0 {
+        message += fmt.Sprintf(" at [%s]", se.Path)
+    }
+
+    return message
+}
+
+func (se *SyntaxError) Unwrap() error {
+    return se.Err
+}
+
+// Is allows every SyntaxError to be matched against ErrSyntax.
+func (se *SyntaxError) Is(target error) bool {
+    return target == ErrSyntax
+}
+
+// isUnbalanced returns true if the input closed an object with a ']' or a list
+// with a '}'.
+func (se *SyntaxError) isUnbalanced() bool {
+    switch se.Actual {
+    case "'}'":
+        return strings.Contains(se.Expected, "']'") == true
+    case "']'":
+        return strings.Contains(se.Expected, "'}'") == true
+    }
+
+    return false
+}
+
+// unbalancedError reports a delimiter that doesn't close the innermost object
+// or list. The tokenizers already check this, so this only happens if they
+// let something through.
+func (p *Parser) unbalancedError(expected string, closer rune) error {
+    return &SyntaxError{
+        Position: p.span.Start,
+        Path: p.currentLocation().Path(),
+        Expected: expected,
+        Actual: fmt.Sprintf("%q", closer),
+        Err: ErrUnbalancedDelimiters,
+    }
+}
+
+// describeToken renders a token from a tokenSource for an error message.
+func describeToken(t json.Token) string {
+    switch t := t.(type) {
+    case json.Delim:
+        return fmt.Sprintf("'%c'", rune(t))
+    case string:
+        return strconv.Quote(t)
+    case nil:
+        return "null"
+    }
+
+    return fmt.Sprintf("%v", t)
+}
+
+// unwrapError returns the error that was panicked with, without the stack
+// that go-logging attached to it. The public API returns errors this way so
+// that errors.Is and errors.As can see into them. Anything else that was
+// panicked with (e.g. a string from a runtime panic in a reader) is returned
+// as an error, so that recovering from it can't panic again.
+func unwrapError(state interface{}) error {
+    err, ok := state.(error)
+    if ok == false {
+        return fmt.Errorf("%v", state)
+    }
+
+    for {
+        wrapped, ok := err.(*goerrors.Error)
+        if ok == false {
+            return err
+        }
+
+        err = wrapped.Err
+    }
+}
+
+var (
+    // decoderExpectations maps the descriptions in json.Decoder's syntax
+    // errors to what was expected.
+    decoderExpectations = []struct {
+        context string
+        expected string
+    }{
+        {"looking for beginning of object key string", "object key"},
+        {"looking for beginning of value", "value"},
+        {"after object key:value pair", "',' or '}'"},
+        {"after object key", "':'"},
+        {"after array element", "',' or ']'"},
+        {"after top-level value", "end of value"},
+        {"in string", "string"},
+        {"in \\u hexadecimal", "string"},
+        {"numeric literal", "number"},
+        {"in literal true", "true"},
+        {"in literal false", "false"},
+        {"in literal null", "null"},
+    }
+)
+
+// describeDecoderError extracts what was expected and what was found from the
+// message of a json.Decoder syntax error (e.g. "invalid character '3' after
+// array element").
+func describeDecoderError(jse *json.SyntaxError) (expected, actual string) {
+    message := jse.Error()
+
+    const prefix = "invalid character "
+    if strings.HasPrefix(message, prefix) == false {
+        return "", ""
+    }
+
+    rest := message[len(prefix):]
+
+    // The character is quoted and might itself be a quote.
+    end := strings.Index(rest[1:], "' ")
+    if end == -1 {
+        return "", ""
+    }
+
+    actual = rest[:end + 2]
+    context := rest[end + 3:]
+
+    for _, de := range decoderExpectations {
+        if strings.Contains(context, de.context) == true {
+            return de.expected, actual
+        }
+    }
+
+    return "", actual
+}
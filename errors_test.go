@@ -0,0 +1,125 @@
+package jsonreader
+
+import (
+    "testing"
+    "strings"
+    "errors"
+    "io"
+)
+
+// nextError returns the first error from Next().
+func nextError(p *Parser) error {
+    for {
+        _, err := p.Next()
+        if err == io.EOF {
+            return nil
+        } else if err != nil {
+            return err
+        }
+    }
+}
+
+func TestSyntaxError(t *testing.T) {
+    for _, native := range []bool{false, true} {
+        options := make([]ParserOption, 0)
+        if native == false {
+            options = append(options, WithStandardDecoder())
+        }
+
+        p := NewParser(strings.NewReader("{\"aa\": [1,\n  2 3]}"), options...)
+
+        err := nextError(p)
+
+        var se *SyntaxError
+        if errors.As(err, &se) == false {
+            t.Fatalf("Expected a SyntaxError (native=%v): %v", native, err)
+        } else if errors.Is(err, ErrSyntax) == false {
+            t.Fatalf("Expected SyntaxError to match ErrSyntax (native=%v).", native)
+        }
+
+        if se.Position.Offset != 15 || se.Position.Line != 2 || se.Position.Column != 5 {
+            t.Fatalf("Position not correct (native=%v): %v", native, se.Position)
+        } else if se.Path.String() != "$.aa[2]" {
+            t.Fatalf("Path not correct (native=%v): [%s]", native, se.Path)
+        } else if se.Expected != "',' or ']'" || se.Actual != "'3'" {
+            t.Fatalf("Expected/actual not correct (native=%v): [%s] [%s]", native, se.Expected, se.Actual)
+        }
+
+        // The error is sticky.
+        _, err = p.Next()
+        if errors.As(err, &se) == false {
+            t.Fatalf("Expected the same error again (native=%v): %v", native, err)
+        }
+    }
+}
+
+func TestSyntaxError_UnbalancedDelimiters(t *testing.T) {
+    for _, native := range []bool{false, true} {
+        options := make([]ParserOption, 0)
+        if native == false {
+            options = append(options, WithStandardDecoder())
+        }
+
+        p := NewParser(strings.NewReader(`{"aa": 1]`), options...)
+
+        err := nextError(p)
+        if errors.Is(err, ErrUnbalancedDelimiters) == false {
+            t.Fatalf("Expected unbalanced-delimiters error (native=%v): %v", native, err)
+        }
+    }
+}
+
+func TestSyntaxError_UnexpectedEOF(t *testing.T) {
+    for _, native := range []bool{false, true} {
+        options := make([]ParserOption, 0)
+        if native == false {
+            options = append(options, WithStandardDecoder())
+        }
+
+        p := NewParser(strings.NewReader(`[{"aa": "bb`), options...)
+
+        err := nextError(p)
+
+        var se *SyntaxError
+        if errors.Is(err, io.ErrUnexpectedEOF) == false {
+            t.Fatalf("Expected unexpected-EOF error (native=%v): %v", native, err)
+        } else if errors.As(err, &se) == false || se.Actual != "EOF" {
+            t.Fatalf("Expected a SyntaxError at EOF (native=%v): %v", native, err)
+        }
+    }
+}
+
+func TestParser_Skip_NotInContainer(t *testing.T) {
+    p := NewParser(strings.NewReader(`[1]`))
+
+    err := p.Skip()
+    if errors.Is(err, ErrNotInContainer) == false {
+        t.Fatalf("Expected not-in-container error: %v", err)
+    }
+
+    // The parser can still be used.
+    err = nextError(p)
+    if err != nil {
+        t.Fatalf("Parser not usable after failed skip: %v", err)
+    }
+}
+
+func TestParseSelector_InvalidSelector(t *testing.T) {
+    _, err := ParseSelector("locations[*]")
+    if errors.Is(err, ErrInvalidSelector) == false {
+        t.Fatalf("Expected invalid-selector error: %v", err)
+    }
+}
+
+func TestDescribeDecoderError(t *testing.T) {
+    p := NewParser(strings.NewReader(`{"aa" '}`))
+
+    err := nextError(p)
+
+    var se *SyntaxError
+    if errors.As(err, &se) == false {
+        t.Fatalf("Expected a SyntaxError: %v", err)
+    } else if se.Expected != "':'" || se.Actual != `'\''` {
+        t.Fatalf("Expected/actual not correct: [%s] [%s]", se.Expected, se.Actual)
+    }
+}
@@ -0,0 +1,175 @@
+package jsonreader
+
+import (
+    "errors"
+    "io"
+
+    "github.com/dsoprea/go-logging"
+)
+
+var (
+    // ErrSkipSubtree can be returned by a Handler callback to skip part of the
+    // input. From OnObjectStart or OnListStart, the object or list is skipped
+    // (and its end callback isn't called). From any other callback, the rest
+    // of the enclosing object or list is skipped (and its end callback isn't
+    // called).
+    ErrSkipSubtree = errors.New("skip subtree")
+
+    // ErrStopParsing can be returned by a Handler callback to stop parsing.
+    // ParseWith then returns nil.
+    ErrStopParsing = errors.New("stop parsing")
+)
+
+// Handler receives the structure of the input from ParseWith. Every callback
+// gets the path of what it describes. If a callback returns an error, parsing
+// stops and ParseWith returns it (except for ErrSkipSubtree and
+// ErrStopParsing).
+type Handler interface {
+    // OnObjectStart is called when an object starts.
+    OnObjectStart(path Path) error
+
+    // OnKey is called for every key of an object. The path is the path of the
+    // key's value.
+    OnKey(path Path, key string) error
+
+    // OnValue is called for every scalar value, whether it's in an object, in
+    // a list, or at the top level. A null is nil. See NumberMode for the types
+    // that numbers are given as.
+    OnValue(path Path, value interface{}) error
+
+    // OnObjectEnd is called when an object ends with the keys of the object
+    // that have scalar values. If WithOrderedObjects was given, this is the
+    // map of the OrderedSimpleObject.
+    OnObjectEnd(path Path, object SimpleObject) error
+
+    // OnListStart is called when a list starts.
+    OnListStart(path Path) error
+
+    // OnListEnd is called when a list ends.
+    OnListEnd(path Path) error
+}
+
+// BaseHandler implements every Handler callback by doing nothing. Embed it to
+// only implement the callbacks that you need.
+type BaseHandler struct{}
+
+func (BaseHandler) OnObjectStart(path Path) error {
+    return nil
+}
+
+func (BaseHandler) OnKey(path Path, key string) error {
+    return nil
+}
+
+func (BaseHandler) OnValue(path Path, value interface{}) error {
+    return nil
+}
+
+func (BaseHandler) OnObjectEnd(path Path, object SimpleObject) error {
+    return nil
+}
+
+func (BaseHandler) OnListStart(path Path) error {
+    return nil
+}
+
+func (BaseHandler) OnListEnd(path Path) error {
+    return nil
+}
+
+// ParseWith parses the input and calls the handler for everything in it. The
+// options are the same as for NewParser. Document boundaries aren't reported
+// to the handler, and a RecordError stops parsing and is returned.
+func ParseWith(r io.Reader, handler Handler, options ...ParserOption) (err error) {
+    defer func() {
+        if state := recover(); state != nil {
+            err = unwrapError(state)
+        }
+    }()
+
+    p := NewParser(r, options...)
+
+    for {
+        token, err := p.Next()
+        if err == io.EOF {
+            break
+        }
+
+        log.PanicIf(err)
+
+        err = p.handleToken(handler, token)
+        if errors.Is(err, ErrStopParsing) == true {
+            break
+        } else if errors.Is(err, ErrSkipSubtree) == true {
+            err := p.skipSubtree()
+            log.PanicIf(err)
+
+            continue
+        }
+
+        log.PanicIf(err)
+    }
+
+    return nil
+}
+
+// handleToken calls the handler callback for the token.
+func (p *Parser) handleToken(handler Handler, token Token) error {
+    switch t := token.Value().(type) {
+    case ObjectOpen:
+        return handler.OnObjectStart(p.Path())
+    case ListOpen:
+        return handler.OnListStart(p.Path())
+    case ObjectKey:
+        return handler.OnKey(p.Path(), string(t))
+    case ObjectValue:
+        return handler.OnValue(p.Path(), t.Value())
+    case Null:
+        return handler.OnValue(p.Path(), nil)
+    case SimpleObject:
+        // This immediately follows the ObjectClose of every object that
+        // wasn't skipped.
+        return handler.OnObjectEnd(p.Path(), t)
+    case OrderedSimpleObject:
+        return handler.OnObjectEnd(p.Path(), t.SimpleObject())
+    case ListClose:
+        return handler.OnListEnd(p.Path())
+    case ObjectClose, SimpleList, Object, OrderedObject, DocumentStart, DocumentEnd:
+        return nil
+    case RecordError:
+        return t
+    }
+
+    // Anything else is a scalar that isn't the value of an object key.
+    return handler.OnValue(p.Path(), token.Value())
+}
+
+// skipSubtree skips the rest of the innermost object or list that is open,
+// including its ObjectClose or ListClose.
+func (p *Parser) skipSubtree() (err error) {
+    defer func() {
+        if state := recover(); state != nil {
+            err = unwrapError(state)
+        }
+    }()
+
+    err = p.Skip()
+    if err == ErrNotInContainer {
+        // We're at the top level, so there's nothing else to skip.
+        return nil
+    }
+
+    log.PanicIf(err)
+
+    // Drop anything that was already queued (e.g. an Object) along with the
+    // end of the container.
+    for {
+        token, err := p.Next()
+        log.PanicIf(err)
+
+        switch token.Kind() {
+        case KindObjectClose, KindListClose:
+            return nil
+        }
+    }
+}
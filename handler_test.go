@@ -0,0 +1,240 @@
+package jsonreader
+
+import (
+    "testing"
+    "strings"
+    "fmt"
+    "errors"
+    "sort"
+
+    "github.com/dsoprea/go-logging"
+)
+
+// recordingHandler records every callback. If a callback is for the path in
+// returnAt, it returns returnErr.
+type recordingHandler struct {
+    events []string
+
+    returnAt string
+    returnErr error
+}
+
+func (rh *recordingHandler) record(path Path, format string, args ...interface{}) error {
+    rh.events = append(rh.events, fmt.Sprintf(format, args...) + " " + path.String())
+
+    if path.String() == rh.returnAt {
+        return rh.returnErr
+    }
+
+    return nil
+}
+
+func (rh *recordingHandler) OnObjectStart(path Path) error {
+    return rh.record(path, "OBJECT-START")
+}
+
+func (rh *recordingHandler) OnKey(path Path, key string) error {
+    return rh.record(path, "KEY=%s", key)
+}
+
+func (rh *recordingHandler) OnValue(path Path, value interface{}) error {
+    return rh.record(path, "VALUE=%v", value)
+}
+
+func (rh *recordingHandler) OnObjectEnd(path Path, object SimpleObject) error {
+    keys := make([]string, 0, len(object))
+    for key := range object {
+        keys = append(keys, key)
+    }
+
+    sort.Strings(keys)
+
+    return rh.record(path, "OBJECT-END=%s", strings.Join(keys, ","))
+}
+
+func (rh *recordingHandler) OnListStart(path Path) error {
+    return rh.record(path, "LIST-START")
+}
+
+func (rh *recordingHandler) OnListEnd(path Path) error {
+    return rh.record(path, "LIST-END")
+}
+
+func TestParseWith(t *testing.T) {
+    r := strings.NewReader(`{"aa": 1, "bb": [true, null, {"cc": "x"}], "dd": "y"}`)
+
+    rh := new(recordingHandler)
+
+    err := ParseWith(r, rh)
+    log.PanicIf(err)
+
+    expected := []string {
+        "OBJECT-START $",
+        "KEY=aa $.aa",
+        "VALUE=1 $.aa",
+        "KEY=bb $.bb",
+        "LIST-START $.bb",
+        "VALUE=true $.bb[0]",
+        "VALUE=<nil> $.bb[1]",
+        "OBJECT-START $.bb[2]",
+        "KEY=cc $.bb[2].cc",
+        "VALUE=x $.bb[2].cc",
+        "OBJECT-END=cc $.bb[2]",
+        "LIST-END $.bb",
+        "KEY=dd $.dd",
+        "VALUE=y $.dd",
+        "OBJECT-END=aa,dd $",
+    }
+
+    if strings.Join(rh.events, "\n") != strings.Join(expected, "\n") {
+        t.Fatalf("Events not correct:\n%s", strings.Join(rh.events, "\n"))
+    }
+}
+
+func TestParseWith_SkipSubtree(t *testing.T) {
+    r := strings.NewReader(`{"aa": {"bb": 1, "cc": [2, 3]}, "dd": [4, 5, 6], "ee": 7}`)
+
+    rh := new(recordingHandler)
+
+    skipper := &skipObjectHandler{
+        recordingHandler: rh,
+        path: "$.aa",
+    }
+
+    err := ParseWith(r, skipper)
+    log.PanicIf(err)
+
+    expected := []string {
+        "OBJECT-START $",
+        "KEY=aa $.aa",
+        "KEY=dd $.dd",
+        "LIST-START $.dd",
+        "VALUE=4 $.dd[0]",
+        "VALUE=5 $.dd[1]",
+        "VALUE=6 $.dd[2]",
+        "LIST-END $.dd",
+        "KEY=ee $.ee",
+        "VALUE=7 $.ee",
+        "OBJECT-END=ee $",
+    }
+
+    if strings.Join(rh.events, "\n") != strings.Join(expected, "\n") {
+        t.Fatalf("Events not correct:\n%s", strings.Join(rh.events, "\n"))
+    }
+}
+
+// skipObjectHandler skips the object at the given path.
+type skipObjectHandler struct {
+    *recordingHandler
+    path string
+}
+
+func (soh *skipObjectHandler) OnObjectStart(path Path) error {
+    if path.String() == soh.path {
+        return ErrSkipSubtree
+    }
+
+    return soh.recordingHandler.OnObjectStart(path)
+}
+
+func TestParseWith_SkipSubtree_FromValue(t *testing.T) {
+    r := strings.NewReader(`{"aa": [1, 2, 3], "bb": {"cc": 4, "dd": 5}}`)
+
+    rh := &recordingHandler{
+        returnAt: "$.aa[1]",
+        returnErr: ErrSkipSubtree,
+    }
+
+    err := ParseWith(r, rh)
+    log.PanicIf(err)
+
+    expected := []string {
+        "OBJECT-START $",
+        "KEY=aa $.aa",
+        "LIST-START $.aa",
+        "VALUE=1 $.aa[0]",
+        "VALUE=2 $.aa[1]",
+        "KEY=bb $.bb",
+        "OBJECT-START $.bb",
+        "KEY=cc $.bb.cc",
+        "VALUE=4 $.bb.cc",
+        "KEY=dd $.bb.dd",
+        "VALUE=5 $.bb.dd",
+        "OBJECT-END=cc,dd $.bb",
+        "OBJECT-END= $",
+    }
+
+    if strings.Join(rh.events, "\n") != strings.Join(expected, "\n") {
+        t.Fatalf("Events not correct:\n%s", strings.Join(rh.events, "\n"))
+    }
+}
+
+func TestParseWith_StopParsing(t *testing.T) {
+    r := strings.NewReader(`[1, 2, 3, {"aa": `)
+
+    rh := &recordingHandler{
+        returnAt: "$[1]",
+        returnErr: ErrStopParsing,
+    }
+
+    // The input is never read far enough to find that it's truncated.
+    err := ParseWith(r, rh)
+    log.PanicIf(err)
+
+    expected := []string {
+        "LIST-START $",
+        "VALUE=1 $[0]",
+        "VALUE=2 $[1]",
+    }
+
+    if strings.Join(rh.events, "\n") != strings.Join(expected, "\n") {
+        t.Fatalf("Events not correct:\n%s", strings.Join(rh.events, "\n"))
+    }
+}
+
+func TestParseWith_HandlerError(t *testing.T) {
+    r := strings.NewReader(`[1, 2, 3]`)
+
+    handlerErr := errors.New("handler failed")
+
+    rh := &recordingHandler{
+        returnAt: "$[1]",
+        returnErr: handlerErr,
+    }
+
+    err := ParseWith(r, rh)
+    if err != handlerErr {
+        t.Fatalf("Expected the handler's error: %v", err)
+    } else if len(rh.events) != 3 {
+        t.Fatalf("Expected parsing to stop: %v", rh.events)
+    }
+}
+
+// panicHandler panics with a string from OnValue.
+type panicHandler struct {
+    BaseHandler
+}
+
+func (panicHandler) OnValue(path Path, value interface{}) error {
+    panic("handler failed")
+}
+
+func TestParseWith_HandlerPanic(t *testing.T) {
+    r := strings.NewReader(`[1, 2, 3]`)
+
+    err := ParseWith(r, panicHandler{})
+    if err == nil || err.Error() != "handler failed" {
+        t.Fatalf("Expected the handler's panic as an error: %v", err)
+    }
+}
+
+func TestParseWith_SyntaxError(t *testing.T) {
+    r := strings.NewReader(`[1, 2`)
+
+    err := ParseWith(r, BaseHandler{})
+
+    var se *SyntaxError
+    if errors.As(err, &se) == false {
+        t.Fatalf("Expected a SyntaxError: %v", err)
+    }
+}
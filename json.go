@@ -2,6 +2,8 @@ package jsonreader
 
 import (
     "io"
+    "iter"
+    "context"
     // "fmt"
     // "strings"
 
@@ -36,162 +38,536 @@ func (ov ObjectValue) Value() interface{} {
 
 type Value interface{}
 
+// Null represents a JSON null that is not the value of an object key. A null
+// object value is sent as an ObjectValue with a nil value.
+type Null struct{}
+
 type SimpleObject map[string]interface{}
 
 type Parser struct {
-    d *json.Decoder
+    source tokenSource
+    tracker *lineTracker
+
+    // span is where the token that we're currently processing is.
+    span Span
+
+    // err is the first error encountered while parsing.
+    err error
+
+    // ctx is the context that the goroutine started by Parse is bound to.
+    ctx context.Context
+
+    // frames has one entry for the top level plus one for each object or list
+    // that we're currently inside of. This is the stack that the recursion
+    // would otherwise keep for us.
+    frames []parseFrame
+
+    // chains is where the delimiterChain nodes of the frames are allocated
+    // from.
+    chains []delimiterChain
+
+    // pending are the tokens that we've produced but that haven't been
+    // returned by Next() yet. A single decoder token can produce more than one
+    // of ours.
+    pending []Token
+    pendingIndex int
+
+    // last is the location of the token most recently returned by Next().
+    last tokenLocation
+
+    done bool
 
-    delimiterStack []rune
-    simpleObjectStack []map[string]interface{}
+    numberMode NumberMode
+    nativeScanner bool
+
+    documentMode DocumentMode
+
+    // records splits the input into documents if there is more than one.
+    records *recordReader
+
+    // inDocument indicates that we've started a document and haven't yet
+    // reached its end.
+    inDocument bool
+
+    recordErrors bool
+    recordErrorCount int
+
+    selectors []*Selector
+    materialize *MaterializeOptions
+
+    simpleLists bool
+    simpleListsInObjects bool
+    orderedObjects bool
+
+    // decodeCallback is called for the selected values while DecodeAt is
+    // running.
+    decodeCallback func(dec Decoder) error
 }
 
-func NewParser(r io.Reader) *Parser {
-    d := json.NewDecoder(r)
+// parseFrame is the state for the top level or one object or list.
+type parseFrame struct {
+    dc *delimiterChain
+
+    // start is where the object or list opened.
+    start Position
 
-    return &Parser{
-        d: d,
+    // i lets us keep track of whether we're on the key or value when
+    // processing an object. When processing a list, it's the current index.
+    i int
 
-        delimiterStack: make([]rune, 0),
-        simpleObjectStack: make([]map[string]interface{}, 0),
+    previousKey string
+
+    // simpleObject collects the keys having scalar values. It's only
+    // allocated for objects.
+    simpleObject map[string]interface{}
+
+    // simpleKeys are the keys of simpleObject in order. It's only allocated
+    // if ordered objects were requested.
+    simpleKeys []string
+
+    // simpleList collects the scalar elements of a list. It's only allocated
+    // for lists and only if SimpleLists were requested.
+    simpleList SimpleList
+
+    // hasContainer indicates that a list has an object or list in it.
+    hasContainer bool
+
+    // selected indicates that this container is at or under a path matched by
+    // a selector (or that there are no selectors), so everything in it is
+    // produced.
+    selected bool
+
+    // selectorStates are the partial matches of the selectors against the
+    // path of this container. They're only relevant if it isn't selected.
+    selectorStates []selectorState
+
+    // skipped indicates that Skip() was called for this container.
+    skipped bool
+
+    // materializing indicates that we're building the complete value of this
+    // container, either because it was selected or because it's inside of
+    // another container that we're building.
+    materializing bool
+
+    // emitObject indicates that an Object token is produced for this
+    // container when it closes.
+    emitObject bool
+
+    // materializeDepth is the nesting level relative to the outermost
+    // container being built.
+    materializeDepth int
+
+    // materializeCount counts the values in the outermost container being
+    // built. It's shared with all of its descendants.
+    materializeCount *int
+
+    materializedObject map[string]interface{}
+    materializedList []interface{}
+
+    // materializedKeys are the keys of materializedObject in order. It's only
+    // allocated if ordered objects were requested.
+    materializedKeys []string
+}
+
+// cursor returns the position that we're currently at within the object or
+// list. There is no position at the top level.
+func (pf *parseFrame) cursor() (element PathElement, ok bool) {
+    switch pf.dc.Delimiter() {
+    case '{':
+        return PathElement{Key: pf.previousKey}, true
+    case '[':
+        return PathElement{Index: pf.i, IsIndex: true}, true
     }
+
+    return PathElement{}, false
 }
 
-func (p *Parser) popDelimiter() (r rune, err error) {
+// ParserOption configures optional behavior when passed to NewParser.
+type ParserOption func(p *Parser)
+
+// WithNumberMode determines which type numbers are produced as. The default is
+// NumberModeFloat64. The mode is applied consistently to Value, ObjectValue,
+// and SimpleObject.
+func WithNumberMode(mode NumberMode) ParserOption {
+    return func(p *Parser) {
+        p.numberMode = mode
+    }
+}
+
+// WithNativeScanner reads the input with the scanner in this package. This is
+// the default, so it only undoes WithStandardDecoder.
+func WithNativeScanner() ParserOption {
+    return func(p *Parser) {
+        p.nativeScanner = true
+    }
+}
+
+// WithStandardDecoder reads the input with json.Decoder rather than with the
+// scanner in this package. The tokens are the same, but it allocates more, and
+// a value that is skipped (by Skip() or because the selectors can't match
+// anything in it) is still read into memory whole before it's discarded.
+func WithStandardDecoder() ParserOption {
+    return func(p *Parser) {
+        p.nativeScanner = false
+    }
+}
+
+// WithSelectors only produces the tokens that are at or under a path matched by
+// any of the selectors. The rest of the input is still consumed. If no
+// selectors are given, every token is produced.
+func WithSelectors(selectors ...*Selector) ParserOption {
+    return func(p *Parser) {
+        p.selectors = selectors
+    }
+}
+
+func NewParser(r io.Reader, options ...ParserOption) *Parser {
+    p := &Parser{
+        frames: make([]parseFrame, 1),
+        pending: make([]Token, 0),
+        nativeScanner: true,
+    }
+
+    for _, option := range options {
+        option(p)
+    }
+
+    p.resetRoot()
+
+    if p.documentMode == DocumentModeLines {
+        // Every document gets its own source.
+        p.records = newRecordReader(r, '\n')
+    } else if p.documentMode == DocumentModeSequence {
+        p.records = newRecordReader(r, recordSeparator)
+    } else {
+        p.tracker = newLineTracker(r)
+        p.source = p.newSource(p.tracker)
+    }
+
+    return p
+}
+
+// resetRoot sets up the top level for a new document.
+func (p *Parser) resetRoot() {
+    p.frames = p.frames[:1]
+    p.frames[0] = parseFrame{}
+
+    root := &p.frames[0]
+    if len(p.selectors) > 0 {
+        root.selectorStates, root.selected = initialSelectorStates(p.selectors)
+    } else {
+        root.selected = true
+    }
+}
+
+// newSource creates the tokenSource for the given reader.
+func (p *Parser) newSource(r io.Reader) tokenSource {
+    // We need the literal text in order to produce anything but a float64.
+    useNumber := p.numberMode != NumberModeFloat64
+
+    if p.nativeScanner == true {
+        // Keep the buffer from the last document.
+        if s, ok := p.source.(*scanner); ok == true {
+            s.reset(r)
+            return s
+        }
+
+        s := newScanner(r)
+        s.useNumber = useNumber
+
+        return s
+    }
+
+    ds := newDecoderSource(r)
+    if useNumber == true {
+        ds.UseNumber()
+    }
+
+    return ds
+}
+
+// send delivers a token to the consumer, giving up if the context is done
+// first.
+func (p *Parser) send(c chan<- interface{}, token interface{}) {
+    select {
+    case c <- token:
+    case <-p.ctx.Done():
+        log.Panic(p.ctx.Err())
+    }
+}
+
+// emit queues a token to be returned by Next(). The token is located at the
+// current position of the current frame, so containers must be emitted before
+// they're pushed and after they're popped.
+func (p *Parser) emit(token interface{}) {
+    p.emitKind(kindOf(token), token)
+}
+
+// emitKind queues a token whose kind is already known. For an ObjectKey, the
+// value is nil, and for an ObjectValue, it's only the value (the key is the
+// location's element), so that neither has to be boxed.
+func (p *Parser) emitKind(kind Kind, value interface{}) {
+    current := p.currentFrame()
+
+    element, hasElement := current.cursor()
+
+    // Drop anything that isn't under a selected path.
+    if current.selected == false {
+        if hasElement == false || matchSelectors(current.selectorStates, element) == false {
+            return
+        }
+    }
+
+    p.pending = append(p.pending, Token{
+        kind: kind,
+        value: value,
+        location: tokenLocation{
+            dc: current.dc,
+            element: element,
+            hasElement: hasElement,
+            span: p.span,
+        },
+    })
+}
+
+// emitClose queues the ObjectClose or ListClose of a container along with how
+// many children it had.
+func (p *Parser) emitClose(token interface{}, children int) {
+    n := len(p.pending)
+
+    p.emit(token)
+
+    if len(p.pending) > n {
+        p.pending[n].location.children = children
+    }
+}
+
+// currentFrame returns the innermost object or list that we're inside of, or
+// the top level.
+func (p *Parser) currentFrame() *parseFrame {
+    return &p.frames[len(p.frames) - 1]
+}
+
+// popFrame leaves the innermost object or list, which the given delimiter
+// should be closing.
+func (p *Parser) popFrame(closer rune) (frame parseFrame, err error) {
     defer func() {
         if state := recover(); state != nil {
-            err = log.Wrap(err)
+            err = unwrapError(state)
         }
     }()
 
-    len_ := len(p.delimiterStack)
-    if len_ == 0 {
-        log.Panicf("unbalanced delimiters")
+    len_ := len(p.frames)
+    if len_ <= 1 {
+        log.Panic(p.unbalancedError("value", closer))
+    }
+
+    frame = p.frames[len_ - 1]
+
+    if opener := frame.dc.Delimiter(); (opener == '{') != (closer == '}') {
+        expected := "'}'"
+        if opener == '[' {
+            expected = "']'"
+        }
+
+        log.Panic(p.unbalancedError(expected, closer))
     }
 
-    var last rune
-    last, p.delimiterStack = p.delimiterStack[len_ - 1], p.delimiterStack[:len_ - 1]
+    p.frames = p.frames[:len_ - 1]
 
-    return last, nil
+    return frame, nil
 }
 
-// ObjectContext is relevant if we're processing through an object.
+// ObjectContext is relevant if we're processing through an object. Get()
+// returns the key for "ObjectKey".
 type ObjectContext struct {
-    context map[string]interface{}
+    key string
 }
 
 func (oc ObjectContext) Get(key string) interface{} {
-    return oc.context[key]
+    if key == "ObjectKey" {
+        return oc.key
+    }
+
+    return nil
 }
 
-// ListContext is relevant if we're processing through a list.
+// ListContext is relevant if we're processing through a list. Get() returns
+// the index for "ListIndex".
 type ListContext struct {
-    context map[string]interface{}
+    index int
 }
 
 func (lc ListContext) Get(key string) interface{} {
-    return lc.context[key]
+    if key == "ListIndex" {
+        return lc.index
+    }
+
+    return nil
 }
 
 type Context interface {
     Get(key string) interface{}
 }
 
+// delimiterChain is an object or list along with the ones that it's inside
+// of. A nil chain is the top level. Nodes are never modified once they're
+// created, so tokens can keep referring to them.
 type delimiterChain struct {
     parent *delimiterChain
 
     delimiter rune
-    context Context
+
+    // element is where the object or list is in its parent. The root
+    // container has the index of the document.
+    element PathElement
 
     depth int
 }
 
-func (dc delimiterChain) Add(delimiter rune, context Context) delimiterChain {
-    return delimiterChain{
-        parent: &dc,
-        delimiter: delimiter,
-        context: context,
-        depth: dc.depth + 1,
+// chainSlabSize is how many nodes are allocated at once.
+const chainSlabSize = 64
+
+// addDelimiter creates the node of an object or list that is opening in the
+// given one. The nodes come from a slab that is replaced (rather than grown)
+// once it's full, so the earlier ones never move.
+func (p *Parser) addDelimiter(parent *delimiterChain, delimiter rune, element PathElement) *delimiterChain {
+    if len(p.chains) == cap(p.chains) {
+        p.chains = make([]delimiterChain, 0, chainSlabSize)
     }
+
+    p.chains = append(p.chains, delimiterChain{
+        parent: parent,
+        delimiter: delimiter,
+        element: element,
+        depth: parent.Depth() + 1,
+    })
+
+    return &p.chains[len(p.chains) - 1]
 }
 
-func (dc delimiterChain) Delimiter() rune {
+func (dc *delimiterChain) Delimiter() rune {
+    if dc == nil {
+        return 0
+    }
+
     return dc.delimiter
 }
 
-func (dc delimiterChain) Context() interface{} {
-    return dc.context
-}
+// Depth returns the depth of the object or list, which is zero for the top
+// level.
+func (dc *delimiterChain) Depth() int {
+    if dc == nil {
+        return 0
+    }
 
-type StackItem struct {
-    Delimiter rune
-    Context Context
+    return dc.depth
 }
 
-func (dc delimiterChain) stack(s []StackItem) {
-    s[dc.depth] = StackItem{
-        Delimiter: dc.delimiter,
-        Context: dc.context,
+// Context returns where the object or list is in its parent.
+func (dc *delimiterChain) Context() Context {
+    if dc == nil {
+        return nil
     }
 
-    if dc.parent != nil {
-        dc.parent.stack(s)
+    if dc.element.IsIndex == true {
+        return &ListContext{index: dc.element.Index}
     }
-}
 
-func (dc delimiterChain) Stack() []StackItem {
-    s := make([]StackItem, dc.depth + 1)
-    dc.stack(s)
+    return &ObjectContext{key: dc.element.Key}
+}
 
-    return s
+type StackItem struct {
+    Delimiter rune
+    Context Context
 }
 
-func (p *Parser) getContextFromCurrent(dc delimiterChain, dctx map[string]interface{}) Context {
-    if dc.Delimiter() == '{' {
-        return &ObjectContext{ context: map[string]interface{} { "ObjectKey": dctx["ObjectKey"] } }
-    } else {
-        return &ListContext{ context: map[string]interface{} { "ListIndex": dctx["ListIndex"] } }
+func (dc *delimiterChain) Stack() []StackItem {
+    s := make([]StackItem, dc.Depth() + 1)
+
+    for ; dc != nil; dc = dc.parent {
+        s[dc.depth] = StackItem{
+            Delimiter: dc.delimiter,
+            Context: dc.Context(),
+        }
     }
+
+    return s
 }
 
 // processDelimiter manages the ascending or descending of child structures.
-func (p *Parser) processDelimiter(c chan<- interface{}, r rune, dc delimiterChain, dctx map[string]interface{}) (ascend bool, err error) {
+func (p *Parser) processDelimiter(r rune) (err error) {
     defer func() {
         if state := recover(); state != nil {
-            err = log.Wrap(err)
+            err = unwrapError(state)
         }
     }()
 
-    if r == '{' {
-        // Entering an object.
+    current := p.currentFrame()
 
-        p.delimiterStack = append(p.delimiterStack, r)
+    if r == '{' || r == '[' {
+        // Entering an object or a list.
 
-        c <- ObjectOpen(r)
+        if r == '{' {
+            p.emit(ObjectOpen(r))
+        } else {
+            p.emit(ListOpen(r))
+        }
+
+        // The root container has no path element of its own, but it's
+        // recorded as the index of the document.
+        element, ok := current.cursor()
+        if ok == false {
+            element = PathElement{Index: current.i, IsIndex: true}
+        }
+
+        frame := parseFrame{
+            dc: p.addDelimiter(current.dc, r, element),
+            start: p.span.Start,
+            selected: current.selected,
+        }
+
+        if frame.selected == false {
+            if ok == true {
+                frame.selectorStates, frame.selected = advanceSelectors(current.selectorStates, element)
+            } else {
+                // The root container has the same (empty) path as the top
+                // level.
+                frame.selectorStates = current.selectorStates
+            }
+        }
 
         // Create an instance to add any keys having scalar values.
-        p.simpleObjectStack = append(p.simpleObjectStack, make(map[string]interface{}))
+        if r == '{' {
+            frame.simpleObject = make(map[string]interface{})
+
+            if p.orderedObjects == true {
+                frame.simpleKeys = make([]string, 0)
+            }
+        }
 
-        context := p.getContextFromCurrent(dc, dctx)
-        childDc := dc.Add(r, context)
+        p.startSimpleList(current, &frame)
 
-        err = p.parse(c, childDc)
+        err := p.startMaterializing(current, &frame, r)
         log.PanicIf(err)
 
-        return false, nil
+        p.frames = append(p.frames, frame)
+
+        return nil
     } else if r == '}' {
         // Leaving an object.
 
-        last, err := p.popDelimiter()
+        last, err := p.popFrame(r)
         log.PanicIf(err)
 
-        if last != '{' {
-            log.Panicf("object closer unbalanced")
-        }
+        // An object has a key and a value for every member.
+        p.emitClose(ObjectClose(r), last.i / 2)
 
-        c <- ObjectClose(r)
+        // Whatever we produce for the whole object covers all of it.
+        p.span.Start = last.start
 
         // fmt.Printf("End of object:\n")
-        // for i, si := range dc.Stack() {
+        // for i, si := range last.dc.Stack() {
         //     indent := strings.Repeat("  ", i + 1)
 
         //     if i == 0 {
@@ -211,192 +587,347 @@ func (p *Parser) processDelimiter(c chan<- interface{}, r rune, dc delimiterChai
         // Also, feed a whole object that we've added any keys and
         // scalar values that we've encountered to.
 
-        len_ := len(p.simpleObjectStack)
-        var lastObject map[string]interface{}
-        lastObject, p.simpleObjectStack = p.simpleObjectStack[len_ - 1], p.simpleObjectStack[:len_ - 1]
-        c <- SimpleObject(lastObject)
-
-        return true, nil
-    } else if r == '[' {
-        // Entering a list.
+        if last.skipped == false {
+            p.emit(last.finishedSimpleObject())
+        }
 
-        p.delimiterStack = append(p.delimiterStack, r)
+        err = p.finishMaterializing(&last, p.currentFrame())
+        log.PanicIf(err)
 
-        c <- ListOpen(r)
+        // The object was one item in its parent.
+        p.currentFrame().i++
 
-        context := p.getContextFromCurrent(dc, dctx)
-        childDc := dc.Add(r, context)
+        return nil
+    } else if r == ']' {
+        // Leaving a list.
 
-        err = p.parse(c, childDc)
+        last, err := p.popFrame(r)
         log.PanicIf(err)
 
-        return false, nil
-    } else if r == ']' {
-        // Leaving a list.
+        p.emitClose(ListClose(r), last.i)
+
+        p.span.Start = last.start
+
+        p.finishSimpleList(&last, p.currentFrame())
 
-        last, err := p.popDelimiter()
+        err = p.finishMaterializing(&last, p.currentFrame())
         log.PanicIf(err)
 
-        if last != '[' {
-            log.Panicf("list closer unbalanced")
+        // The list was one item in its parent.
+        p.currentFrame().i++
+
+        return nil
+    }
+
+    // Should never reach here.
+    log.Panicf("delimiter not valid: [%c]", r)
+    return nil
+}
+
+// processScalar handles any token that isn't a delimiter.
+func (p *Parser) processScalar(t json.Token) {
+    current := p.currentFrame()
+
+    isInObject := current.dc.Delimiter() == '{'
+
+    if isInObject == true && current.i % 2 == 0 {
+        // We're on an object key.
+
+        if key, ok := t.(string); ok == true {
+            p.processKey(key)
         }
 
-        c <- ListClose(r)
+        return
+    }
+
+    if n, ok := t.(json.Number); ok == true {
+        value, err := convertNumber(n, p.numberMode)
+        log.PanicIf(err)
 
-        return true, nil
+        t = value
     }
 
-    // Should never reach here.
-    log.Panic("delimiter processing panic")
-    return false, nil
+    // The value stays in the same interface from here on so that it's only
+    // boxed once.
+
+    if isInObject == true {
+        // If we're processing the value for a key, set the pair into the last
+        // simple object that we created. Keep a null in the simple object so
+        // that it can be distinguished from a missing key.
+        current.setSimple(current.previousKey, t)
+
+        p.emitKind(KindObjectValue, t)
+    } else if t == nil {
+        p.emitKind(KindNull, Null{})
+    } else {
+        // We're on a bool, number, or string but not in an object.
+
+        p.emitKind(KindValue, t)
+    }
+
+    if isInObject == false {
+        p.collectSimpleList(current, t)
+    }
+
+    if current.materializing == true {
+        err := p.materializeValue(current, t)
+        log.PanicIf(err)
+    }
+
+    current.i++
+}
+
+// processKey handles an object key.
+func (p *Parser) processKey(key string) {
+    current := p.currentFrame()
+
+    current.previousKey = key
+    p.emitKind(KindObjectKey, nil)
+
+    current.i++
 }
 
-func (p *Parser) parse(c chan<- interface{}, dc delimiterChain) (err error) {
+// step reads the next token from the decoder and queues whatever tokens it
+// produces.
+func (p *Parser) step() (err error) {
     defer func() {
         if state := recover(); state != nil {
-            err = log.Wrap(err)
+            err = unwrapError(state)
         }
     }()
 
-// TODO(dustin): !! Implement a second channel and a waitgroup for error-handling from the goroutine.
+    if p.documentMode != DocumentModeSingle {
+        handled, err := p.stepDocument()
+        log.PanicIf(err)
+
+        if handled == true {
+            return nil
+        }
+    }
+
+    if p.decodeCallback != nil {
+        decoded, err := p.decodeSelected()
+        log.PanicIf(err)
+
+        if decoded == true {
+            return nil
+        }
+    }
 
-    previousKey := ""
+    skipped, err := p.skipUnselected()
+    log.PanicIf(err)
 
-    // Lets us kep track of whether we're on the key or value when processing an
-    // object.
-    i := 0
-    isInObject := dc.Delimiter() == '{'
+    if skipped == true {
+        return nil
+    }
 
-    for {
-        t, err := p.d.Token()
+    if s, ok := p.source.(*scanner); ok == true {
+        // Our scanner can give us the token without boxing it, so keys and
+        // delimiters never are and values are boxed only once.
+        kind, err := s.nextToken()
         if err != nil {
-            if err == io.EOF {
-                break
-            }
+            p.endOfSource(err)
+            return nil
+        }
 
+        p.markToken()
+
+        switch kind {
+        case scanKindDelim:
+            err := p.processDelimiter(rune(s.delim))
             log.PanicIf(err)
+        case scanKindKey:
+            p.processKey(s.str)
+        default:
+            p.processScalar(s.tokenValue(kind))
         }
 
-        if delimiter, ok := t.(json.Delim); ok == true {
-            r := rune(delimiter)
+        return nil
+    }
+
+    t, err := p.source.Token()
+    if err != nil {
+        p.endOfSource(err)
+        return nil
+    }
+
+    p.markToken()
+
+    if delimiter, ok := t.(json.Delim); ok == true {
+        err := p.processDelimiter(rune(delimiter))
+        log.PanicIf(err)
+    } else {
+        p.processScalar(t)
+    }
+
+    return nil
+}
+
+// endOfSource handles an error from reading a token. A clean EOF ends the
+// document (or the input), and anything else panics.
+func (p *Parser) endOfSource(err error) {
+    if err != io.EOF {
+        log.Panic(p.sourceError(err))
+    }
+
+    // The decoder reports a clean EOF even if we're still inside of an object
+    // or list.
+    if len(p.frames) > 1 {
+        log.Panic(p.sourceError(io.ErrUnexpectedEOF))
+    }
+
+    if p.records != nil {
+        p.endDocument()
+        return
+    }
+
+    p.done = true
+}
+
+// Next returns the next token. It returns io.EOF once all of the input has
+// been parsed. This walks the same state machine as Parse without a goroutine
+// or a channel, so tokens are produced on the caller's stack, one at a time.
+func (p *Parser) Next() (token Token, err error) {
+    if p.err != nil {
+        return Token{}, p.err
+    }
 
-            dctx := map[string]interface{} {
-                // Relevant if we're processing through an object.
-                "ObjectKey": previousKey,
+    for p.pendingIndex >= len(p.pending) {
+        if p.done == true {
+            return Token{}, io.EOF
+        }
 
-                // Relevant if we're processing through a list.
-                "ListIndex": i,
+        // Check the context on every step rather than only when sending, so
+        // that ParseContext also stops while nothing is being produced (e.g.
+        // while skipping what the selectors don't match).
+        if p.ctx != nil {
+            if err := p.ctx.Err(); err != nil {
+                p.err = err
+                return Token{}, err
             }
+        }
 
-            ascend, err := p.processDelimiter(c, r, dc, dctx)
-            log.PanicIf(err)
+        p.pending = p.pending[:0]
+        p.pendingIndex = 0
+
+        err := p.step()
+        if err != nil {
+            p.err = unwrapError(err)
+            return Token{}, p.err
+        }
+    }
+
+    token = p.pending[p.pendingIndex]
+
+    // Don't hold a reference to the token after it's been delivered.
+    p.pending[p.pendingIndex] = Token{}
+    p.pendingIndex++
+
+    p.last = token.location
 
-            // We've finished processing an object or a list.
-            if ascend == true {
-                return nil
+    return token, nil
+}
+
+// Path returns the path of the token most recently returned by Next() (or
+// All()). For an ObjectKey or ObjectValue, this is the path of the value. For
+// ObjectOpen, ObjectClose, ListOpen, ListClose, and SimpleObject, this is the
+// path of the object or list. This is not useful in conjunction with Parse(),
+// which parses ahead of the consumer.
+func (p *Parser) Path() Path {
+    return p.last.Path()
+}
+
+// All returns an iterator over the remaining tokens. Parsing happens as the
+// loop runs, so breaking out of the loop stops reading from the reader. If
+// parsing fails, the error is yielded once and iteration stops.
+func (p *Parser) All() iter.Seq2[Token, error] {
+    return func(yield func(Token, error) bool) {
+        for {
+            token, err := p.Next()
+            if err == io.EOF {
+                return
+            } else if err != nil {
+                yield(Token{}, err)
+                return
             }
-        } else {
-            isObjectValue := isInObject && i % 2 == 1
-
-            switch t.(type) {
-            case bool:
-                value := t.(bool)
-
-                // If we're processing the value for a key, set the pair into
-                // the last simple object that we created.
-                if isObjectValue {
-                    len_ := len(p.simpleObjectStack)
-                    lastObject := p.simpleObjectStack[len_ - 1]
-                    lastObject[previousKey] = value
-
-                    c <- ObjectValue{
-                        key: previousKey,
-                        value: value,
-                    }
-                } else {
-                    c <- Value(value)
-                }
-            case float64:
-                value := t.(float64)
-
-                // If we're processing the value for a key, set the pair into
-                // the last simple object that we created.
-                if isObjectValue {
-                    len_ := len(p.simpleObjectStack)
-                    lastObject := p.simpleObjectStack[len_ - 1]
-                    lastObject[previousKey] = value
-
-                    c <- ObjectValue{
-                        key: previousKey,
-                        value: value,
-                    }
-                } else {
-                    c <- Value(value)
-                }
-            case string:
-                value := t.(string)
-
-                if isInObject {
-                    if isObjectValue {
-                        // We're on an object value.
-
-                        len_ := len(p.simpleObjectStack)
-                        lastObject := p.simpleObjectStack[len_ - 1]
-                        lastObject[previousKey] = value
-
-                        c <- ObjectValue{
-                            key: previousKey,
-                            value: value,
-                        }
-                    } else if i % 2 == 0 {
-                        // We're on an object key.
-
-                        previousKey = value
-                        c <- ObjectKey(value)
-                    }
-                } else {
-                    // We're on a string but not in an object (not an object
-                    // key, not an object value).
-
-                    c <- Value(value)
-                }
+
+            if yield(token, nil) == false {
+                return
             }
         }
-
-        i++
     }
+}
+
+// Parse starts parsing in a goroutine and sends every token to the given
+// channel. The tokens are sent as they are returned by Token.Value() (e.g. an
+// ObjectOpen or a float64), which is how this package has always produced
+// them. The channel is always closed when parsing stops, whether or not it
+// was successful. Once it has been closed, call Err() to find out whether
+// parsing failed.
+func (p *Parser) Parse(c chan<- interface{}) (err error) {
+    defer func() {
+        if state := recover(); state != nil {
+            err = unwrapError(state)
+        }
+    }()
+
+    err = p.ParseContext(context.Background(), c)
+    log.PanicIf(err)
 
     return nil
 }
 
-func (p *Parser) Parse(c chan<- interface{}) (err error) {
+// ParseContext is like Parse but stops parsing when the context is cancelled.
+// This is how a consumer that stops reading from the channel early allows the
+// goroutine (and its reference to the reader) to be released. The channel is
+// closed and Err() will return the context's error. Note that a read that is
+// already blocked on the underlying io.Reader can not be interrupted.
+func (p *Parser) ParseContext(ctx context.Context, c chan<- interface{}) (err error) {
     defer func() {
         if state := recover(); state != nil {
-            err = log.Wrap(err)
+            err = unwrapError(state)
         }
     }()
 
+    p.ctx = ctx
+
     go func() {
+        // Record the error before closing the channel so that it's visible to
+        // a caller that waits on the channel.
+        defer close(c)
+
         defer func() {
             if state := recover(); state != nil {
-                log.Panic(err)
+                p.err = unwrapError(state)
             }
         }()
 
-        dc := delimiterChain{}
-        err = p.parse(c, dc)
-        log.PanicIf(err)
+        for {
+            token, err := p.Next()
+            if err == io.EOF {
+                break
+            }
 
-        close(c)
+            log.PanicIf(err)
+
+            p.send(c, token.Value())
+        }
     }()
 
-    return err
+    return nil
+}
+
+// Err returns the first syntax or I/O error encountered while parsing, or nil
+// if the input was parsed successfully. When using Parse, it is only
+// meaningful after the channel has been closed.
+func (p *Parser) Err() error {
+    return p.err
 }
 
 func (p *Parser) ParseToTokenSlice(r io.Reader) (ts []interface{}, err error) {
     defer func() {
         if state := recover(); state != nil {
-            err = log.Wrap(err)
+            err = unwrapError(state)
         }
     }()
 
@@ -410,5 +941,8 @@ func (p *Parser) ParseToTokenSlice(r io.Reader) (ts []interface{}, err error) {
         ts = append(ts, token)
     }
 
+    err = p.Err()
+    log.PanicIf(err)
+
     return ts, nil
 }
@@ -2,12 +2,17 @@ package jsonreader
 
 import (
     "testing"
+    "context"
+    "runtime"
+    "time"
     "os"
     "path"
     "fmt"
     "io"
     "strings"
     "sort"
+    "errors"
+    "bytes"
 
     "github.com/dsoprea/go-logging"
 )
@@ -16,10 +21,99 @@ var (
     testingAssetsPath = ""
 )
 
+func flattenToken(token interface{}) string {
+    flat := ""
+
+    // Tokens from Next() and All() are rendered the same as the ones from the
+    // channel.
+    if t, ok := token.(Token); ok == true {
+        token = t.Value()
+    }
+
+    switch token.(type) {
+    case ObjectOpen:
+        flat = "/OBJECTOPEN"
+    case ObjectClose:
+        flat = "/OBJECTCLOSE"
+    case ListOpen:
+        flat = "/LISTOPEN"
+    case ListClose:
+        flat = "/LISTCLOSE"
+    case ObjectKey:
+        flat = fmt.Sprintf(":%s", token)
+    case ObjectValue:
+        ov := token.(ObjectValue)
+        v := ov.Value()
+
+        switch v.(type) {
+        case nil:
+            flat = fmt.Sprintf("[%s] N", ov.Key())
+        case bool:
+            flat = fmt.Sprintf("[%s] B %v", ov.Key(), v)
+        case float64:
+            flat = fmt.Sprintf("[%s] F %f", ov.Key(), v)
+        case int64:
+            flat = fmt.Sprintf("[%s] I %d", ov.Key(), v)
+        case string:
+            flat = fmt.Sprintf("[%s] S %s", ov.Key(), v)
+        }
+    case Null:
+        flat = "#NULL"
+    case SimpleList:
+        flat = fmt.Sprintf("@LIST=%v", []interface{}(token.(SimpleList)))
+    case OrderedSimpleObject:
+        // The keys are already in a deterministic order.
+
+        couplets := make([]string, 0)
+        token.(OrderedSimpleObject).Range(func(key string, value interface{}) bool {
+            couplets = append(couplets, fmt.Sprintf("%s:%v", key, value))
+            return true
+        })
+
+        flat = fmt.Sprintf("@@%s", strings.Join(couplets, " "))
+    case DocumentStart:
+        flat = fmt.Sprintf("/DOCUMENTSTART=%d", token.(DocumentStart).Line)
+    case DocumentEnd:
+        flat = fmt.Sprintf("/DOCUMENTEND=%d", token.(DocumentEnd).Line)
+    case bool:
+        flat = fmt.Sprintf("#BOOL=%v", token)
+    case float64:
+        flat = fmt.Sprintf("#FLOAT64=%f", token)
+    case int64:
+        flat = fmt.Sprintf("#INT64=%d", token)
+    case string:
+        flat = fmt.Sprintf("#STRING=%s", token)
+    case SimpleObject:
+        // Produce adeterministic string representation by sorting the
+        // keys.
+
+        o := map[string]interface{}(token.(SimpleObject))
+
+        keys := make([]string, len(o))
+        i := 0
+        for k, _ := range o {
+            keys[i] = k
+            i++
+        }
+
+        ss := sort.StringSlice(keys)
+        ss.Sort()
+
+        couplets := make([]string, len(o))
+        for j, k := range ss {
+            couplets[j] = fmt.Sprintf("%s:%v", k, o[k])
+        }
+
+        flat = fmt.Sprintf("@%s", strings.Join(couplets, " "))
+    }
+
+    return flat
+}
+
 func flattenStream(r io.Reader) (ts []string, err error) {
     defer func() {
         if state := recover(); state != nil {
-            err = log.Wrap(err)
+            err = log.Wrap(state.(error))
         }
     }()
 
@@ -31,62 +125,32 @@ func flattenStream(r io.Reader) (ts []string, err error) {
 
     ts = make([]string, 0)
     for token := range c {
-        flat := ""
-
-        switch token.(type) {
-        case ObjectOpen:
-            flat = "/OBJECTOPEN"
-        case ObjectClose:
-            flat = "/OBJECTCLOSE"
-        case ListOpen:
-            flat = "/LISTOPEN"
-        case ListClose:
-            flat = "/LISTCLOSE"
-        case ObjectKey:
-            flat = fmt.Sprintf(":%s", token)
-        case ObjectValue:
-            ov := token.(ObjectValue)
-            v := ov.Value()
-
-            switch v.(type) {
-            case float64:
-                flat = fmt.Sprintf("[%s] F %f", ov.Key(), v)
-            case int64:
-                flat = fmt.Sprintf("[%s] I %d", ov.Key(), v)
-            case string:
-                flat = fmt.Sprintf("[%s] S %s", ov.Key(), v)
-            }
-        case float64:
-            flat = fmt.Sprintf("#FLOAT64=%f", token)
-        case int64:
-            flat = fmt.Sprintf("#INT64=%d", token)
-        case string:
-            flat = fmt.Sprintf("#STRING=%s", token)
-        case SimpleObject:
-            // Produce adeterministic string representation by sorting the
-            // keys.
-
-            o := map[string]interface{}(token.(SimpleObject))
+        ts = append(ts, flattenToken(token))
+    }
 
-            keys := make([]string, len(o))
-            i := 0
-            for k, _ := range o {
-                keys[i] = k
-                i++
-            }
+    err = p.Err()
+    log.PanicIf(err)
 
-            ss := sort.StringSlice(keys)
-            ss.Sort()
+    return ts, nil
+}
 
-            couplets := make([]string, len(o))
-            for j, k := range ss {
-                couplets[j] = fmt.Sprintf("%s:%v", k, o[k])
-            }
+func flattenNext(p *Parser) (ts []string, err error) {
+    defer func() {
+        if state := recover(); state != nil {
+            err = log.Wrap(state.(error))
+        }
+    }()
 
-            flat = fmt.Sprintf("@%s", strings.Join(couplets, " "))
+    ts = make([]string, 0)
+    for {
+        token, err := p.Next()
+        if err == io.EOF {
+            break
         }
 
-        ts = append(ts, flat)
+        log.PanicIf(err)
+
+        ts = append(ts, flattenToken(token))
     }
 
     return ts, nil
@@ -342,6 +406,357 @@ func TestParseScalar1(t *testing.T) {
     }
 }
 
+func TestNext_Data1(t *testing.T) {
+    filepath := path.Join(testingAssetsPath, "data1.json")
+
+    f, err := os.Open(filepath)
+    log.PanicIf(err)
+
+    defer f.Close()
+
+    expected, err := flattenStream(f)
+    log.PanicIf(err)
+
+    _, err = f.Seek(0, io.SeekStart)
+    log.PanicIf(err)
+
+    p := NewParser(f)
+
+    ts, err := flattenNext(p)
+    log.PanicIf(err)
+
+    if len(ts) != len(expected) {
+        t.Fatalf("Token count (%d) does not equal channel token count (%d).", len(ts), len(expected))
+    }
+
+    for i, entry := range ts {
+        if expected[i] != entry {
+            t.Fatalf("Item (%d) value [%s] should be [%s].", i, entry, expected[i])
+        }
+    }
+}
+
+func TestNext_SyntaxError(t *testing.T) {
+    r := strings.NewReader(`{"aa": 1, ]`)
+
+    p := NewParser(r)
+
+    _, err := flattenNext(p)
+    if err == nil {
+        t.Fatalf("Expected error for invalid syntax.")
+    }
+
+    // The error is sticky.
+    _, err = p.Next()
+    if err == nil || err == io.EOF {
+        t.Fatalf("Expected the same error again: %v", err)
+    }
+}
+
+// byteReader returns one byte per read and counts the reads.
+type byteReader struct {
+    data []byte
+    reads int
+}
+
+func (br *byteReader) Read(b []byte) (n int, err error) {
+    if len(br.data) == 0 {
+        return 0, io.EOF
+    }
+
+    br.reads++
+
+    b[0] = br.data[0]
+    br.data = br.data[1:]
+
+    return 1, nil
+}
+
+func TestAll(t *testing.T) {
+    r := strings.NewReader(`[1, "aa", {"bb": true}]`)
+
+    p := NewParser(r)
+
+    ts := make([]string, 0)
+    for token, err := range p.All() {
+        log.PanicIf(err)
+
+        ts = append(ts, flattenToken(token))
+    }
+
+    expected := []string{
+        "/LISTOPEN",
+        "#FLOAT64=1.000000",
+        "#STRING=aa",
+        "/OBJECTOPEN",
+        ":bb",
+        "[bb] B true",
+        "/OBJECTCLOSE",
+        "@bb:true",
+        "/LISTCLOSE",
+    }
+
+    if len(ts) != len(expected) {
+        t.Fatalf("Token count not correct: %v", ts)
+    }
+
+    for i, entry := range ts {
+        if expected[i] != entry {
+            t.Fatalf("Item (%d) value [%s] should be [%s].", i, entry, expected[i])
+        }
+    }
+}
+
+func TestAll_Break(t *testing.T) {
+    data := []byte(`[1, 2, 3, 4, 5, 6, 7, 8]`)
+    br := &byteReader{
+        data: data,
+    }
+
+    p := NewParser(br)
+
+    for _, err := range p.All() {
+        log.PanicIf(err)
+        break
+    }
+
+    if br.reads >= len(data) {
+        t.Fatalf("All of the data was read.")
+    }
+}
+
+func TestAll_Error(t *testing.T) {
+    r := strings.NewReader(`[1, }`)
+
+    p := NewParser(r)
+
+    var lastErr error
+    for _, err := range p.All() {
+        lastErr = err
+    }
+
+    if lastErr == nil {
+        t.Fatalf("Expected error for invalid syntax.")
+    }
+}
+
+func TestNext_Null(t *testing.T) {
+    r := strings.NewReader(`{"aa": null, "bb": [null], "cc": 1}`)
+
+    p := NewParser(r)
+
+    ts := make([]string, 0)
+    var so SimpleObject
+    for token, err := range p.All() {
+        log.PanicIf(err)
+
+        if o, ok := token.Value().(SimpleObject); ok == true {
+            so = o
+        }
+
+        ts = append(ts, flattenToken(token))
+    }
+
+    expected := []string{
+        "/OBJECTOPEN",
+        ":aa",
+        "[aa] N",
+        ":bb",
+        "/LISTOPEN",
+        "#NULL",
+        "/LISTCLOSE",
+        ":cc",
+        "[cc] F 1.000000",
+        "/OBJECTCLOSE",
+        "@aa:<nil> cc:1",
+    }
+
+    if len(ts) != len(expected) {
+        t.Fatalf("Token count not correct: %v", ts)
+    }
+
+    for i, entry := range ts {
+        if expected[i] != entry {
+            t.Fatalf("Item (%d) value [%s] should be [%s].", i, entry, expected[i])
+        }
+    }
+
+    if value, found := so["aa"]; found == false {
+        t.Fatalf("Null key should be present in simple object.")
+    } else if value != nil {
+        t.Fatalf("Null key should have a nil value: %v", value)
+    }
+}
+
+func TestParse_SyntaxError(t *testing.T) {
+    r := strings.NewReader(`[1, }`)
+
+    c := make(chan interface{}, 0)
+
+    p := NewParser(r)
+    err := p.Parse(c)
+    log.PanicIf(err)
+
+    for _ = range c {
+    }
+
+    if p.Err() == nil {
+        t.Fatalf("Expected error for invalid syntax.")
+    }
+}
+
+func TestParse_Truncated(t *testing.T) {
+    r := strings.NewReader(`{"aa": [1, 2`)
+
+    c := make(chan interface{}, 0)
+
+    p := NewParser(r)
+    err := p.Parse(c)
+    log.PanicIf(err)
+
+    for _ = range c {
+    }
+
+    if errors.Is(p.Err(), io.ErrUnexpectedEOF) == false {
+        t.Fatalf("Expected unexpected-EOF error: %v", p.Err())
+    }
+}
+
+func TestParse_NoError(t *testing.T) {
+    r := strings.NewReader(`{"aa": [1, 2]}`)
+
+    c := make(chan interface{}, 0)
+
+    p := NewParser(r)
+    err := p.Parse(c)
+    log.PanicIf(err)
+
+    for _ = range c {
+    }
+
+    if p.Err() != nil {
+        t.Fatalf("Expected no error: %v", p.Err())
+    }
+}
+
+func TestParseContext_Cancel(t *testing.T) {
+    r := strings.NewReader(`[1, 2, 3, 4, 5, 6, 7, 8]`)
+
+    ctx, cancel := context.WithCancel(context.Background())
+
+    c := make(chan interface{}, 0)
+
+    p := NewParser(r)
+    err := p.ParseContext(ctx, c)
+    log.PanicIf(err)
+
+    // Abandon the stream after the first token.
+    <-c
+    cancel()
+
+    // The channel must be closed shortly after cancelling rather than leaving
+    // the goroutine blocked on a send.
+    for _ = range c {
+    }
+
+    if errors.Is(p.Err(), context.Canceled) == false {
+        t.Fatalf("Expected cancellation error: %v", p.Err())
+    }
+}
+
+func TestParseContext_NoLeak(t *testing.T) {
+    before := runtime.NumGoroutine()
+
+    for i := 0; i < 10; i++ {
+        r := strings.NewReader(`[1, 2, 3, 4, 5, 6, 7, 8]`)
+
+        ctx, cancel := context.WithCancel(context.Background())
+
+        c := make(chan interface{}, 0)
+
+        p := NewParser(r)
+        err := p.ParseContext(ctx, c)
+        log.PanicIf(err)
+
+        <-c
+        cancel()
+    }
+
+    deadline := time.Now().Add(time.Second)
+    for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+        time.Sleep(time.Millisecond * 10)
+    }
+
+    if runtime.NumGoroutine() > before {
+        t.Fatalf("Goroutines were leaked: (%d) > (%d)", runtime.NumGoroutine(), before)
+    }
+}
+
+func TestParseContext_CancelWhileSkipping(t *testing.T) {
+    b := new(bytes.Buffer)
+    b.WriteString(`[`)
+
+    for i := 0; i < 100000; i++ {
+        if i > 0 {
+            b.WriteString(`,`)
+        }
+
+        b.WriteString(`{"aa": 1}`)
+    }
+
+    b.WriteString(`]`)
+
+    ctx, cancel := context.WithCancel(context.Background())
+    cancel()
+
+    c := make(chan interface{}, 0)
+
+    // Nothing is selected, so there's never anything to send.
+    br := &byteReader{data: b.Bytes()}
+    p := NewParser(br, WithSelectors(mustParseSelector("$.bb")))
+
+    err := p.ParseContext(ctx, c)
+    log.PanicIf(err)
+
+    for _ = range c {
+    }
+
+    if errors.Is(p.Err(), context.Canceled) == false {
+        t.Fatalf("Expected cancellation error: %v", p.Err())
+    } else if br.reads > 1000 {
+        t.Fatalf("Too much was read after cancelling: (%d)", br.reads)
+    }
+}
+
+// panicReader panics with a string rather than an error.
+type panicReader struct{}
+
+func (panicReader) Read(b []byte) (n int, err error) {
+    panic("reader failed")
+}
+
+func TestParseContext_NonErrorPanic(t *testing.T) {
+    for _, native := range []bool{false, true} {
+        options := make([]ParserOption, 0)
+        if native == false {
+            options = append(options, WithStandardDecoder())
+        }
+
+        c := make(chan interface{}, 0)
+
+        p := NewParser(panicReader{}, options...)
+        err := p.ParseContext(context.Background(), c)
+        log.PanicIf(err)
+
+        for _ = range c {
+        }
+
+        if p.Err() == nil || strings.Contains(p.Err().Error(), "reader failed") == false {
+            t.Fatalf("Expected the panic as an error (native=%v): %v", native, p.Err())
+        }
+    }
+}
+
 func init() {
     goPath := os.Getenv("GOPATH")
     testingAssetsPath = path.Join(goPath, "src", "github.com", "dsoprea", "go-efficient-json-reader", "testing")
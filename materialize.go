@@ -0,0 +1,217 @@
+package jsonreader
+
+import (
+    "errors"
+
+    "github.com/dsoprea/go-logging"
+)
+
+var (
+    // ErrMaterializeLimit indicates that an object being materialized exceeded
+    // MaterializeOptions.MaxDepth or MaterializeOptions.MaxValues.
+    ErrMaterializeLimit = errors.New("materialize limit exceeded")
+)
+
+// Object is a complete object, including any nested objects (as
+// map[string]interface{}) and lists (as []interface{}). When materializing is
+// enabled, it's produced right after the SimpleObject for the same object.
+type Object map[string]interface{}
+
+// OrderedObject is produced instead of an Object when WithOrderedObjects is
+// given. It has the same keys and values, but remembers the order that the
+// keys were in, and so do the objects nested in it (which are OrderedObjects
+// rather than maps).
+type OrderedObject struct {
+    orderedMap
+}
+
+// Object returns the keys and values as an Object (without the order). It
+// shares the same map, so the nested objects are still OrderedObjects.
+func (oo OrderedObject) Object() Object {
+    return Object(oo.values)
+}
+
+// MaterializeOptions determines which objects are produced as complete Object
+// tokens.
+type MaterializeOptions struct {
+    // Selectors restricts materializing to the objects whose paths are
+    // matched exactly. If empty, every object is materialized.
+    Selectors []*Selector
+
+    // MaxDepth is the maximum number of nested levels in a materialized
+    // object, where an object with only scalar values has a depth of one. Zero
+    // means no limit.
+    MaxDepth int
+
+    // MaxValues is the maximum number of values (including nested objects and
+    // lists) in a materialized object. Zero means no limit.
+    MaxValues int
+}
+
+// WithMaterialize produces an Object token containing the whole subtree of
+// each selected object when it closes. Exceeding the limits is an error
+// (ErrMaterializeLimit).
+func WithMaterialize(options MaterializeOptions) ParserOption {
+    return func(p *Parser) {
+        p.materialize = &options
+    }
+}
+
+// matchesExactly returns true if the selector matches the path itself (rather
+// than anything above it).
+func (s *Selector) matchesExactly(path Path) bool {
+    states, matched := initialSelectorStates([]*Selector{s})
+    for _, pe := range path {
+        states, matched = advanceSelectors(states, pe)
+    }
+
+    return matched
+}
+
+// startMaterializing decides whether a container that's being entered should
+// be built and, if so, prepares the frame for it.
+func (p *Parser) startMaterializing(parent *parseFrame, frame *parseFrame, r rune) (err error) {
+    defer func() {
+        if state := recover(); state != nil {
+            err = unwrapError(state)
+        }
+    }()
+
+    if p.materialize == nil {
+        return nil
+    }
+
+    if r == '{' {
+        if len(p.materialize.Selectors) == 0 {
+            frame.emitObject = true
+        } else {
+            location := tokenLocation{
+                dc: parent.dc,
+            }
+
+            location.element, location.hasElement = parent.cursor()
+            path := location.Path()
+
+            for _, selector := range p.materialize.Selectors {
+                if selector.matchesExactly(path) == true {
+                    frame.emitObject = true
+                    break
+                }
+            }
+        }
+    }
+
+    if parent.materializing == true {
+        // We're part of a larger materialized object.
+
+        frame.materializeDepth = parent.materializeDepth + 1
+        frame.materializeCount = parent.materializeCount
+
+        if p.materialize.MaxDepth > 0 && frame.materializeDepth > p.materialize.MaxDepth {
+            log.Panic(ErrMaterializeLimit)
+        }
+    } else if frame.emitObject == true {
+        frame.materializeDepth = 1
+        frame.materializeCount = new(int)
+    } else {
+        return nil
+    }
+
+    frame.materializing = true
+
+    if r == '{' {
+        frame.materializedObject = make(map[string]interface{})
+
+        if p.orderedObjects == true {
+            frame.materializedKeys = make([]string, 0)
+        }
+    } else {
+        frame.materializedList = make([]interface{}, 0)
+    }
+
+    return nil
+}
+
+// materializeValue adds a value to the container being built for the frame.
+func (p *Parser) materializeValue(frame *parseFrame, value interface{}) (err error) {
+    defer func() {
+        if state := recover(); state != nil {
+            err = unwrapError(state)
+        }
+    }()
+
+    *frame.materializeCount++
+    if p.materialize.MaxValues > 0 && *frame.materializeCount > p.materialize.MaxValues {
+        log.Panic(ErrMaterializeLimit)
+    }
+
+    if frame.dc.Delimiter() == '{' {
+        if frame.materializedKeys != nil {
+            if _, found := frame.materializedObject[frame.previousKey]; found == false {
+                frame.materializedKeys = append(frame.materializedKeys, frame.previousKey)
+            }
+        }
+
+        frame.materializedObject[frame.previousKey] = value
+    } else {
+        frame.materializedList = append(frame.materializedList, value)
+    }
+
+    return nil
+}
+
+// finishMaterializing attaches a container that was built to its parent and
+// produces the Object token if one was requested.
+func (p *Parser) finishMaterializing(last *parseFrame, parent *parseFrame) (err error) {
+    defer func() {
+        if state := recover(); state != nil {
+            err = unwrapError(state)
+        }
+    }()
+
+    if last.materializing == false {
+        return nil
+    }
+
+    // A container that was skipped is incomplete, so it's left out of the
+    // container that it's in rather than being passed off as its value.
+    if parent.materializing == true && last.skipped == false {
+        var value interface{}
+        if last.dc.Delimiter() == '{' {
+            value = last.finishedMaterializedObject()
+        } else {
+            value = last.materializedList
+        }
+
+        err := p.materializeValue(parent, value)
+        log.PanicIf(err)
+    }
+
+    if last.emitObject == true && last.skipped == false {
+        if last.materializedKeys != nil {
+            p.emit(last.finishedMaterializedObject())
+        } else {
+            p.emit(Object(last.materializedObject))
+        }
+    }
+
+    return nil
+}
+
+// finishedMaterializedObject returns the object that was built for a frame as
+// it's set into its parent: an OrderedObject if ordered objects were requested
+// and a map otherwise.
+func (pf *parseFrame) finishedMaterializedObject() interface{} {
+    if pf.materializedKeys != nil {
+        oo := OrderedObject{
+            orderedMap: orderedMap{
+                keys: pf.materializedKeys,
+                values: pf.materializedObject,
+            },
+        }
+
+        return oo
+    }
+
+    return pf.materializedObject
+}
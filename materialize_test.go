@@ -0,0 +1,186 @@
+package jsonreader
+
+import (
+    "testing"
+    "os"
+    "fmt"
+    "path"
+    "reflect"
+    "strings"
+    "errors"
+
+    "encoding/json"
+
+    "github.com/dsoprea/go-logging"
+)
+
+func TestParser_Materialize(t *testing.T) {
+    r := strings.NewReader(`[{"aa": "bb", "cc": 1000.1, "dd": {"subkey1": "subvalue1", "list": [1, [2], null]}}]`)
+
+    p := NewParser(r, WithMaterialize(MaterializeOptions{}))
+
+    objects := make([]Object, 0)
+    for token, err := range p.All() {
+        log.PanicIf(err)
+
+        if o, ok := token.Value().(Object); ok == true {
+            objects = append(objects, o)
+        }
+    }
+
+    if len(objects) != 2 {
+        t.Fatalf("Object count not correct: (%d)", len(objects))
+    }
+
+    nested := Object{
+        "subkey1": "subvalue1",
+        "list": []interface{} { 1.0, []interface{} { 2.0 }, nil },
+    }
+
+    if reflect.DeepEqual(objects[0], nested) != true {
+        t.Fatalf("Nested object not correct: %v", objects[0])
+    }
+
+    expected := Object{
+        "aa": "bb",
+        "cc": 1000.1,
+        "dd": map[string]interface{}(nested),
+    }
+
+    if reflect.DeepEqual(objects[1], expected) != true {
+        t.Fatalf("Object not correct: %v", objects[1])
+    }
+}
+
+func TestParser_Materialize_Ordered(t *testing.T) {
+    r := strings.NewReader(`{"zz": 1, "aa": {"yy": 1.5, "bb": [{"nn": 1, "mm": 2}]}, "mm": null}`)
+
+    p := NewParser(r, WithMaterialize(MaterializeOptions{}), WithOrderedObjects(), WithNumberMode(NumberModeExact))
+
+    var oo OrderedObject
+    for token, err := range p.All() {
+        log.PanicIf(err)
+
+        if token.Kind() == KindObject && token.Depth() == 0 {
+            oo = token.Value().(OrderedObject)
+        }
+    }
+
+    if strings.Join(oo.Keys(), " ") != "zz aa mm" {
+        t.Fatalf("Keys not correct: %v", oo.Keys())
+    } else if len(oo.Object()) != 3 {
+        t.Fatalf("Object not correct: %v", oo.Object())
+    }
+
+    data, err := json.Marshal(oo)
+    log.PanicIf(err)
+
+    if string(data) != `{"zz":1,"aa":{"yy":1.5,"bb":[{"nn":1,"mm":2}]},"mm":null}` {
+        t.Fatalf("JSON not correct: [%s]", string(data))
+    }
+}
+
+func TestParser_Materialize_Skip(t *testing.T) {
+    r := strings.NewReader(`{"a": {"x": 1, "y": 2}, "b": 3, "c": [[1, 2], 4]}`)
+
+    p := NewParser(r, WithMaterialize(MaterializeOptions{}))
+
+    var object Object
+    for token, err := range p.All() {
+        log.PanicIf(err)
+
+        path := token.Path().String()
+        if token.Kind() == KindObjectKey && path == "$.a.x" {
+            err := p.Skip()
+            log.PanicIf(err)
+        } else if token.Kind() == KindListOpen && path == "$.c[0]" {
+            err := p.Skip()
+            log.PanicIf(err)
+        } else if token.Kind() == KindObject {
+            object = token.Value().(Object)
+        }
+    }
+
+    // The skipped object and list are incomplete, so they're left out.
+    expected := Object{
+        "b": 3.0,
+        "c": []interface{} { 4.0 },
+    }
+
+    if reflect.DeepEqual(object, expected) != true {
+        t.Fatalf("Object not correct: %v", object)
+    }
+}
+
+func TestParser_Materialize_Selectors(t *testing.T) {
+    filepath := path.Join(testingAssetsPath, "data1.json")
+
+    f, err := os.Open(filepath)
+    log.PanicIf(err)
+
+    defer f.Close()
+
+    options := MaterializeOptions{
+        Selectors: []*Selector{
+            mustParseSelector("$.locations[*]"),
+        },
+    }
+
+    p := NewParser(f, WithMaterialize(options), WithNumberMode(NumberModeExact))
+
+    objects := make([]Object, 0)
+    for token, err := range p.All() {
+        log.PanicIf(err)
+
+        if o, ok := token.Value().(Object); ok == true {
+            if p.Path().String() != "$.locations[" + fmt.Sprintf("%d", len(objects)) + "]" {
+                t.Fatalf("Object path not correct: [%s]", p.Path())
+            }
+
+            objects = append(objects, o)
+        }
+    }
+
+    if len(objects) != 12 {
+        t.Fatalf("Object count not correct: (%d)", len(objects))
+    }
+
+    activity := objects[7]["activity"].([]interface{})[0].(map[string]interface{})["activity"].([]interface{})
+    if len(activity) != 3 {
+        t.Fatalf("Activity count not correct: (%d)", len(activity))
+    } else if activity[2].(map[string]interface{})["type"] != "IN_VEHICLE" {
+        t.Fatalf("Activity not correct: %v", activity[2])
+    } else if objects[0]["latitudeE7"].(int64) != 265620925 {
+        t.Fatalf("Number not correct: %v", objects[0]["latitudeE7"])
+    }
+}
+
+func TestParser_Materialize_MaxDepth(t *testing.T) {
+    r := strings.NewReader(`{"aa": {"bb": {"cc": 1}}}`)
+
+    p := NewParser(r, WithMaterialize(MaterializeOptions{MaxDepth: 2}))
+
+    var lastErr error
+    for _, err := range p.All() {
+        lastErr = err
+    }
+
+    if errors.Is(lastErr, ErrMaterializeLimit) == false {
+        t.Fatalf("Expected limit error: %v", lastErr)
+    }
+}
+
+func TestParser_Materialize_MaxValues(t *testing.T) {
+    r := strings.NewReader(`{"aa": [1, 2, 3]}`)
+
+    p := NewParser(r, WithMaterialize(MaterializeOptions{MaxValues: 3}))
+
+    var lastErr error
+    for _, err := range p.All() {
+        lastErr = err
+    }
+
+    if errors.Is(lastErr, ErrMaterializeLimit) == false {
+        t.Fatalf("Expected limit error: %v", lastErr)
+    }
+}
@@ -0,0 +1,169 @@
+package jsonreader
+
+import (
+    "math/big"
+    "strconv"
+    "strings"
+
+    "encoding/json"
+
+    "github.com/dsoprea/go-logging"
+)
+
+// NumberMode determines which type numbers are produced as.
+type NumberMode int
+
+const (
+    // NumberModeFloat64 produces every number as a float64. Integers larger
+    // than 2^53 lose precision. This is the default.
+    NumberModeFloat64 NumberMode = iota
+
+    // NumberModeJsonNumber produces every number as a json.Number (the literal
+    // text of the number).
+    NumberModeJsonNumber
+
+    // NumberModeExact produces an int64 for integral numbers that fit, a
+    // *big.Int for integral numbers that don't, and a *big.Float for
+    // everything else. A number is integral by value, so "1e3" and "10.0" are
+    // also produced as int64s. Note that a *big.Float is binary, so a number
+    // like "0.1" is only as close as its precision allows (which is more than
+    // enough for all of the digits that were given) rather than exact. A
+    // number whose exponent is larger than maxExactExponent in either
+    // direction (e.g. "1e100000000") is produced as a json.Number rather than
+    // being expanded.
+    NumberModeExact
+)
+
+// String returns a descriptive name for the mode.
+func (nm NumberMode) String() string {
+    switch nm {
+    case NumberModeFloat64:
+        return "Float64"
+    case NumberModeJsonNumber:
+        return "JsonNumber"
+    case NumberModeExact:
+        return "Exact"
+    }
+
+    return "NumberMode(" + strconv.Itoa(int(nm)) + ")"
+}
+
+// maxExactExponent is the largest decimal exponent (in either direction) that
+// NumberModeExact will expand a number by.
+const maxExactExponent = 1000
+
+// splitDecimal splits the literal text of a number into its significant digits
+// (with the sign) and the power of ten that they're multiplied by. Trailing
+// zeros are moved into the exponent, so the number is integral if the exponent
+// isn't negative. It returns false if the exponent is larger than
+// maxExactExponent in either direction.
+func splitDecimal(s string) (digits string, exponent int, ok bool) {
+    mantissa := s
+    if i := strings.IndexAny(s, "eE"); i >= 0 {
+        mantissa = s[:i]
+
+        e, err := strconv.Atoi(s[i+1:])
+        if err != nil || e > maxExactExponent || e < -maxExactExponent {
+            return "", 0, false
+        }
+
+        exponent = e
+    }
+
+    if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+        exponent -= len(mantissa) - i - 1
+        mantissa = mantissa[:i] + mantissa[i+1:]
+    }
+
+    sign := ""
+    if mantissa[0] == '-' {
+        sign = "-"
+        mantissa = mantissa[1:]
+    }
+
+    mantissa = strings.TrimLeft(mantissa, "0")
+    if mantissa == "" {
+        return "0", 0, true
+    }
+
+    trimmed := strings.TrimRight(mantissa, "0")
+    exponent += len(mantissa) - len(trimmed)
+
+    if exponent > maxExactExponent || exponent < -maxExactExponent {
+        return "", 0, false
+    }
+
+    return sign + trimmed, exponent, true
+}
+
+// convertNumber converts the literal text of a number to the type required by
+// the given mode.
+func convertNumber(n json.Number, mode NumberMode) (value interface{}, err error) {
+    defer func() {
+        if state := recover(); state != nil {
+            err = unwrapError(state)
+        }
+    }()
+
+    switch mode {
+    case NumberModeFloat64:
+        f, err := n.Float64()
+        log.PanicIf(err)
+
+        return f, nil
+    case NumberModeJsonNumber:
+        return n, nil
+    case NumberModeExact:
+        s := n.String()
+
+        // Fast path for plain integers.
+        if strings.ContainsAny(s, ".eE") == false {
+            if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+                return i, nil
+            }
+
+            bi, ok := new(big.Int).SetString(s, 10)
+            if ok == false {
+                log.Panicf("invalid integer: [%s]", s)
+            }
+
+            return bi, nil
+        }
+
+        digits, exponent, ok := splitDecimal(s)
+        if ok == false {
+            // Expanding it would take as much memory and time as the exponent
+            // is large.
+            return n, nil
+        }
+
+        if exponent >= 0 {
+            bi, ok := new(big.Int).SetString(digits, 10)
+            if ok == false {
+                log.Panicf("invalid number: [%s]", s)
+            }
+
+            if exponent > 0 {
+                scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)
+                bi.Mul(bi, scale)
+            }
+
+            if bi.IsInt64() == true {
+                return bi.Int64(), nil
+            }
+
+            return bi, nil
+        }
+
+        // Allow enough precision for all of the digits that were given.
+        precision := uint(len(s)) * 4 + 64
+
+        bf, _, err := big.ParseFloat(s, 10, precision, big.ToNearestEven)
+        log.PanicIf(err)
+
+        return bf, nil
+    }
+
+    log.Panicf("number mode not valid: (%d)", mode)
+    return nil, nil
+}
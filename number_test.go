@@ -0,0 +1,122 @@
+package jsonreader
+
+import (
+    "testing"
+    "math/big"
+    "strings"
+
+    "encoding/json"
+
+    "github.com/dsoprea/go-logging"
+)
+
+func TestConvertNumber_Float64(t *testing.T) {
+    value, err := convertNumber(json.Number("123.45"), NumberModeFloat64)
+    log.PanicIf(err)
+
+    if value.(float64) != 123.45 {
+        t.Fatalf("Value not correct: %v", value)
+    }
+}
+
+func TestConvertNumber_JsonNumber(t *testing.T) {
+    value, err := convertNumber(json.Number("9007199254740993"), NumberModeJsonNumber)
+    log.PanicIf(err)
+
+    if value.(json.Number) != "9007199254740993" {
+        t.Fatalf("Value not correct: %v", value)
+    }
+}
+
+func TestConvertNumber_Exact(t *testing.T) {
+    value, err := convertNumber(json.Number("9007199254740993"), NumberModeExact)
+    log.PanicIf(err)
+
+    if value.(int64) != 9007199254740993 {
+        t.Fatalf("Int64 value not correct: %v", value)
+    }
+
+    value, err = convertNumber(json.Number("-1e3"), NumberModeExact)
+    log.PanicIf(err)
+
+    if value.(int64) != -1000 {
+        t.Fatalf("Integral exponent value not correct: %v", value)
+    }
+
+    value, err = convertNumber(json.Number("123456789012345678901234567890"), NumberModeExact)
+    log.PanicIf(err)
+
+    if value.(*big.Int).String() != "123456789012345678901234567890" {
+        t.Fatalf("Big-int value not correct: %v", value)
+    }
+
+    value, err = convertNumber(json.Number("0.1000000000000000000000000001"), NumberModeExact)
+    log.PanicIf(err)
+
+    if value.(*big.Float).Text('f', 28) != "0.1000000000000000000000000001" {
+        t.Fatalf("Big-float value not correct: %v", value)
+    }
+}
+
+func TestConvertNumber_Exact_LargeExponent(t *testing.T) {
+    value, err := convertNumber(json.Number("1e100"), NumberModeExact)
+    log.PanicIf(err)
+
+    if value.(*big.Int).String() != "1" + strings.Repeat("0", 100) {
+        t.Fatalf("Big-int value not correct: %v", value)
+    }
+
+    value, err = convertNumber(json.Number("12.50e2"), NumberModeExact)
+    log.PanicIf(err)
+
+    if value.(int64) != 1250 {
+        t.Fatalf("Integral fraction value not correct: %v", value)
+    }
+
+    value, err = convertNumber(json.Number("-0.0"), NumberModeExact)
+    log.PanicIf(err)
+
+    if value.(int64) != 0 {
+        t.Fatalf("Zero value not correct: %v", value)
+    }
+
+    // These aren't expanded.
+
+    for _, literal := range []string{"1e100000000", "-1e-100000000", "1e99999999999999999999"} {
+        value, err = convertNumber(json.Number(literal), NumberModeExact)
+        log.PanicIf(err)
+
+        if value.(json.Number) != json.Number(literal) {
+            t.Fatalf("Large-exponent value not correct: %v", value)
+        }
+    }
+}
+
+func TestParser_NumberModeExact(t *testing.T) {
+    r := strings.NewReader(`{"latitudeE7": 265620925, "timestampMs": 1517218739237, "accuracy": 1.5, "list": [9007199254740993]}`)
+
+    p := NewParser(r, WithNumberMode(NumberModeExact))
+
+    var value interface{}
+    var so SimpleObject
+    for token, err := range p.All() {
+        log.PanicIf(err)
+
+        switch token.Kind() {
+        case KindSimpleObject:
+            so = token.Value().(SimpleObject)
+        case KindValue:
+            value = token.Value()
+        }
+    }
+
+    if value.(int64) != 9007199254740993 {
+        t.Fatalf("List value not correct: %v", value)
+    } else if so["latitudeE7"].(int64) != 265620925 {
+        t.Fatalf("Object value not correct: %v", so["latitudeE7"])
+    } else if so["timestampMs"].(int64) != 1517218739237 {
+        t.Fatalf("Object value not correct: %v", so["timestampMs"])
+    } else if f, _ := so["accuracy"].(*big.Float).Float64(); f != 1.5 {
+        t.Fatalf("Object value not correct: %v", so["accuracy"])
+    }
+}
@@ -0,0 +1,182 @@
+package jsonreader
+
+import (
+    "bytes"
+
+    "math/big"
+
+    "encoding/json"
+
+    "github.com/dsoprea/go-logging"
+)
+
+// orderedMap is a map that also remembers the order that its keys were set in.
+// It's what OrderedSimpleObject and OrderedObject are built on.
+type orderedMap struct {
+    keys []string
+    values map[string]interface{}
+}
+
+// OrderedSimpleObject is produced instead of a SimpleObject when
+// WithOrderedObjects is given. It has the same keys and values, but also
+// remembers the order that the keys were in. If a key appears more than once,
+// it keeps its first position and its last value.
+type OrderedSimpleObject struct {
+    orderedMap
+}
+
+// WithOrderedObjects produces an OrderedSimpleObject rather than a SimpleObject
+// after the ObjectClose of every object and, if WithMaterialize is also given,
+// an OrderedObject rather than an Object.
+func WithOrderedObjects() ParserOption {
+    return func(p *Parser) {
+        p.orderedObjects = true
+    }
+}
+
+// Get returns the value of the key and whether the key is present.
+func (om orderedMap) Get(key string) (value interface{}, found bool) {
+    value, found = om.values[key]
+    return value, found
+}
+
+// Keys returns the keys in the order of the input. It must not be modified.
+func (om orderedMap) Keys() []string {
+    return om.keys
+}
+
+// Len returns the number of keys.
+func (om orderedMap) Len() int {
+    return len(om.keys)
+}
+
+// Range calls the callback for every key and value in the order of the input
+// until it returns false.
+func (om orderedMap) Range(callback func(key string, value interface{}) bool) {
+    for _, key := range om.keys {
+        if callback(key, om.values[key]) == false {
+            return
+        }
+    }
+}
+
+// SimpleObject returns the keys and values as a SimpleObject (without the
+// order). It shares the same map.
+func (oso OrderedSimpleObject) SimpleObject() SimpleObject {
+    return SimpleObject(oso.values)
+}
+
+// MarshalJSON encodes the object with its keys in the order of the input.
+func (om orderedMap) MarshalJSON() (data []byte, err error) {
+    defer func() {
+        if state := recover(); state != nil {
+            err = unwrapError(state)
+        }
+    }()
+
+    b := new(bytes.Buffer)
+    b.WriteByte('{')
+
+    for i, key := range om.keys {
+        if i > 0 {
+            b.WriteByte(',')
+        }
+
+        encodedKey, err := json.Marshal(key)
+        log.PanicIf(err)
+
+        b.Write(encodedKey)
+        b.WriteByte(':')
+
+        err = marshalValue(b, om.values[key])
+        log.PanicIf(err)
+    }
+
+    b.WriteByte('}')
+
+    return b.Bytes(), nil
+}
+
+// marshalValue encodes a value of an ordered object. Numbers are always
+// written as number literals (json.Marshal would quote a *big.Float, which
+// only implements MarshalText).
+func marshalValue(b *bytes.Buffer, value interface{}) (err error) {
+    defer func() {
+        if state := recover(); state != nil {
+            err = unwrapError(state)
+        }
+    }()
+
+    switch t := value.(type) {
+    case *big.Float:
+        b.WriteString(t.Text('g', -1))
+    case json.Number:
+        b.WriteString(string(t))
+    case SimpleList:
+        err := marshalList(b, t)
+        log.PanicIf(err)
+    case []interface{}:
+        err := marshalList(b, t)
+        log.PanicIf(err)
+    default:
+        encoded, err := json.Marshal(value)
+        log.PanicIf(err)
+
+        b.Write(encoded)
+    }
+
+    return nil
+}
+
+// marshalList encodes a list of values with marshalValue.
+func marshalList(b *bytes.Buffer, list []interface{}) (err error) {
+    defer func() {
+        if state := recover(); state != nil {
+            err = unwrapError(state)
+        }
+    }()
+
+    b.WriteByte('[')
+
+    for i, value := range list {
+        if i > 0 {
+            b.WriteByte(',')
+        }
+
+        err := marshalValue(b, value)
+        log.PanicIf(err)
+    }
+
+    b.WriteByte(']')
+
+    return nil
+}
+
+// setSimple sets a key having a scalar value into the SimpleObject of the
+// object, remembering the order of the keys if requested.
+func (pf *parseFrame) setSimple(key string, value interface{}) {
+    if pf.simpleKeys != nil {
+        if _, found := pf.simpleObject[key]; found == false {
+            pf.simpleKeys = append(pf.simpleKeys, key)
+        }
+    }
+
+    pf.simpleObject[key] = value
+}
+
+// finishedSimpleObject returns the SimpleObject or OrderedSimpleObject of an
+// object that closed.
+func (pf *parseFrame) finishedSimpleObject() interface{} {
+    if pf.simpleKeys != nil {
+        oso := OrderedSimpleObject{
+            orderedMap: orderedMap{
+                keys: pf.simpleKeys,
+                values: pf.simpleObject,
+            },
+        }
+
+        return oso
+    }
+
+    return SimpleObject(pf.simpleObject)
+}
@@ -0,0 +1,145 @@
+package jsonreader
+
+import (
+    "testing"
+    "strings"
+    "fmt"
+
+    "encoding/json"
+
+    "github.com/dsoprea/go-logging"
+)
+
+func TestParser_OrderedObjects(t *testing.T) {
+    r := strings.NewReader(`{"zz": 1, "aa": {"yy": null, "bb": "x"}, "mm": true, "zz": 2}`)
+
+    p := NewParser(r, WithOrderedObjects())
+
+    objects := make([]string, 0)
+    for token, err := range p.All() {
+        log.PanicIf(err)
+
+        if token.Kind() == KindSimpleObject {
+            objects = append(objects, flattenToken(token) + " " + token.Path().String())
+        }
+    }
+
+    // A repeated key keeps its first position and its last value.
+    expected := []string {
+        "@@yy:<nil> bb:x $.aa",
+        "@@zz:2 mm:true $",
+    }
+
+    if strings.Join(objects, "\n") != strings.Join(expected, "\n") {
+        t.Fatalf("Objects not correct:\n%s", strings.Join(objects, "\n"))
+    }
+}
+
+func TestOrderedSimpleObject(t *testing.T) {
+    r := strings.NewReader(`{"cc": 3, "aa": "x", "bb": null, "tags": ["b", "a"]}`)
+
+    p := NewParser(r, WithOrderedObjects(), WithSimpleListsInObjects(), WithNumberMode(NumberModeExact))
+
+    var oso OrderedSimpleObject
+    for token, err := range p.All() {
+        log.PanicIf(err)
+
+        if token.Kind() == KindSimpleObject {
+            oso = token.Value().(OrderedSimpleObject)
+        }
+    }
+
+    if oso.Len() != 4 {
+        t.Fatalf("Length not correct: (%d)", oso.Len())
+    } else if strings.Join(oso.Keys(), " ") != "cc aa bb tags" {
+        t.Fatalf("Keys not correct: %v", oso.Keys())
+    }
+
+    if value, found := oso.Get("cc"); found == false || value != int64(3) {
+        t.Fatalf("Value not correct: (%v) [%v]", found, value)
+    } else if value, found := oso.Get("bb"); found == false || value != nil {
+        t.Fatalf("Null value not correct: (%v) [%v]", found, value)
+    } else if _, found := oso.Get("dd"); found == true {
+        t.Fatalf("Expected missing key to not be found.")
+    }
+
+    visited := make([]string, 0)
+    oso.Range(func(key string, value interface{}) bool {
+        visited = append(visited, key)
+        return key != "aa"
+    })
+
+    if strings.Join(visited, " ") != "cc aa" {
+        t.Fatalf("Range did not stop: %v", visited)
+    }
+
+    if len(oso.SimpleObject()) != 4 {
+        t.Fatalf("SimpleObject not correct: %v", oso.SimpleObject())
+    }
+
+    data, err := json.Marshal(oso)
+    log.PanicIf(err)
+
+    if string(data) != `{"cc":3,"aa":"x","bb":null,"tags":["b","a"]}` {
+        t.Fatalf("JSON not correct: [%s]", string(data))
+    }
+}
+
+func TestOrderedSimpleObject_MarshalJSON_Empty(t *testing.T) {
+    data, err := json.Marshal(OrderedSimpleObject{})
+    log.PanicIf(err)
+
+    if string(data) != `{}` {
+        t.Fatalf("JSON not correct: [%s]", string(data))
+    }
+}
+
+func TestOrderedSimpleObject_MarshalJSON_Numbers(t *testing.T) {
+    for _, mode := range []NumberMode{NumberModeExact, NumberModeJsonNumber} {
+        r := strings.NewReader(`{"a": 1.5, "b": 2, "c": [0.25, 1e2]}`)
+
+        p := NewParser(r, WithOrderedObjects(), WithSimpleListsInObjects(), WithNumberMode(mode))
+
+        var oso OrderedSimpleObject
+        for token, err := range p.All() {
+            log.PanicIf(err)
+
+            if token.Kind() == KindSimpleObject {
+                oso = token.Value().(OrderedSimpleObject)
+            }
+        }
+
+        data, err := json.Marshal(oso)
+        log.PanicIf(err)
+
+        // Numbers stay numbers, exactly as they were given where possible.
+        expected := `{"a":1.5,"b":2,"c":[0.25,100]}`
+        if mode == NumberModeJsonNumber {
+            expected = `{"a":1.5,"b":2,"c":[0.25,1e2]}`
+        }
+
+        if string(data) != expected {
+            t.Fatalf("JSON not correct for mode [%s]: [%s]", mode, string(data))
+        }
+    }
+}
+
+func TestParallelLines_OrderedSimpleObject(t *testing.T) {
+    data := "{\"bb\": 1, \"aa\": 2}\n[1]\n{\"dd\": 3, \"cc\": 4}\n"
+
+    options := ParallelOptions{
+        Workers: 2,
+        ChunkSize: 1,
+    }
+
+    objects := make([]string, 0)
+    for oso, err := range ParallelLines[OrderedSimpleObject](strings.NewReader(data), options) {
+        log.PanicIf(err)
+
+        objects = append(objects, strings.Join(oso.Keys(), ","))
+    }
+
+    if fmt.Sprintf("%v", objects) != "[bb,aa dd,cc]" {
+        t.Fatalf("Objects not correct: %v", objects)
+    }
+}
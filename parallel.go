@@ -0,0 +1,647 @@
+package jsonreader
+
+import (
+    "bufio"
+    "bytes"
+    "errors"
+    "io"
+    "iter"
+    "runtime"
+    "sync"
+
+    "encoding/json"
+)
+
+const (
+    // defaultParallelChunkSize is the default ParallelOptions.ChunkSize.
+    defaultParallelChunkSize = 1024 * 1024
+)
+
+// ParallelOptions configures ParallelLines and ParallelElements.
+type ParallelOptions struct {
+    // Workers is the number of goroutines parsing at the same time. The
+    // default is GOMAXPROCS.
+    Workers int
+
+    // ChunkSize is roughly how many bytes of input a worker parses at a time.
+    // Chunks are extended to end at a newline (ParallelLines) or at the end of
+    // an element (ParallelElements). The default is 1MB.
+    ChunkSize int
+
+    // Unordered produces the values as soon as they're parsed rather than in
+    // the order of the input.
+    Unordered bool
+
+    // ParserOptions are given to the parser of every chunk (e.g.
+    // WithNumberMode, WithStandardDecoder, or WithRecordErrors). The document
+    // mode is set by ParallelLines and ParallelElements.
+    ParserOptions []ParserOption
+}
+
+// parallelChunk is a run of complete lines.
+type parallelChunk struct {
+    index int
+    data []byte
+
+    // start is where the chunk starts in the whole input.
+    start Position
+
+    // err is a read error that happened instead of reading the chunk.
+    err error
+}
+
+// parallelItem is one value from a chunk or an error. If fatal is true,
+// nothing after it is produced.
+type parallelItem[T any] struct {
+    value T
+    err error
+    fatal bool
+}
+
+type parallelResult[T any] struct {
+    index int
+    items []parallelItem[T]
+}
+
+// ParallelLines parses newline-delimited JSON on several goroutines and
+// returns an iterator over the documents, each decoded into a T (honoring
+// `json` struct tags). If T is SimpleObject (or OrderedSimpleObject), the
+// SimpleObject of every document that is an object is produced instead (and
+// other documents are ignored).
+//
+// The input is split into chunks of complete lines that are parsed by the
+// workers, and the values are produced in the order of the input unless
+// ParallelOptions.Unordered is set. At most two chunks per worker are held in
+// memory.
+//
+// A document that can't be decoded into a T is yielded as a RecordError and
+// iteration continues, as are invalid documents if WithRecordErrors is in
+// ParallelOptions.ParserOptions. Any other error is yielded once and iteration
+// stops. Breaking out of the loop stops all of the goroutines, though a read
+// that's already blocked on the reader can't be interrupted.
+func ParallelLines[T any](r io.Reader, options ParallelOptions) iter.Seq2[T, error] {
+    return func(yield func(T, error) bool) {
+        workers := options.Workers
+        if workers <= 0 {
+            workers = runtime.GOMAXPROCS(0)
+        }
+
+        chunkSize := options.ChunkSize
+        if chunkSize <= 0 {
+            chunkSize = defaultParallelChunkSize
+        }
+
+        runParallel(yield, workers, options.Unordered, func(chunks chan<- parallelChunk, inflight chan struct{}, done <-chan struct{}) {
+            readParallelChunks(r, chunkSize, chunks, inflight, done)
+        }, func(chunk parallelChunk) parallelResult[T] {
+            result := parallelResult[T]{
+                index: chunk.index,
+                items: parseParallelChunk[T](chunk, options.ParserOptions),
+            }
+
+            return result
+        })
+    }
+}
+
+// runParallel runs the producer on its own goroutine and parses the chunks
+// that it sends on the workers. The items are yielded in the order of the
+// chunk indices unless unordered is true. The producer has to acquire a slot
+// in inflight for every chunk and stop when done is closed.
+func runParallel[C any, T any](yield func(T, error) bool, workers int, unordered bool, produce func(chunks chan<- C, inflight chan struct{}, done <-chan struct{}), parse func(chunk C) parallelResult[T]) {
+    // done tells the goroutines to stop if we return early.
+    done := make(chan struct{})
+    defer close(done)
+
+    // inflight bounds the number of chunks that have been read but not yet
+    // yielded.
+    inflight := make(chan struct{}, workers * 2)
+
+    chunks := make(chan C)
+    results := make(chan parallelResult[T])
+
+    go produce(chunks, inflight, done)
+
+    wg := new(sync.WaitGroup)
+    for i := 0; i < workers; i++ {
+        wg.Add(1)
+
+        go func() {
+            defer wg.Done()
+
+            for chunk := range chunks {
+                result := parse(chunk)
+
+                select {
+                case results <- result:
+                case <-done:
+                    return
+                }
+            }
+        }()
+    }
+
+    go func() {
+        wg.Wait()
+        close(results)
+    }()
+
+    // yieldResult yields the items of one chunk. It returns false if we have
+    // to stop.
+    yieldResult := func(result parallelResult[T]) bool {
+        for _, item := range result.items {
+            if yield(item.value, item.err) == false || item.fatal == true {
+                return false
+            }
+        }
+
+        <-inflight
+        return true
+    }
+
+    if unordered == true {
+        for result := range results {
+            if yieldResult(result) == false {
+                return
+            }
+        }
+
+        return
+    }
+
+    // Hold on to the chunks that finish early until the ones before them are
+    // done.
+    waiting := make(map[int]parallelResult[T])
+    next := 0
+
+    for result := range results {
+        waiting[result.index] = result
+
+        for {
+            result, found := waiting[next]
+            if found == false {
+                break
+            }
+
+            delete(waiting, next)
+            next++
+
+            if yieldResult(result) == false {
+                return
+            }
+        }
+    }
+}
+
+// readParallelChunks splits the input into chunks that end at a newline and
+// sends them to the workers.
+func readParallelChunks(r io.Reader, chunkSize int, chunks chan<- parallelChunk, inflight chan struct{}, done <-chan struct{}) {
+    defer close(chunks)
+
+    br := bufio.NewReader(r)
+
+    start := Position{
+        Line: 1,
+        Column: 1,
+    }
+
+    for index := 0; ; index++ {
+        select {
+        case inflight <- struct{}{}:
+        case <-done:
+            return
+        }
+
+        chunk := parallelChunk{
+            index: index,
+            start: start,
+        }
+
+        data := make([]byte, chunkSize)
+
+        n, err := io.ReadFull(br, data)
+        data = data[:n]
+
+        last := false
+        if err == nil {
+            // Finish the last line.
+            rest, err := br.ReadBytes('\n')
+            data = append(data, rest...)
+
+            if err == io.EOF {
+                last = true
+            } else if err != nil {
+                chunk.err = err
+            }
+        } else if err == io.EOF || err == io.ErrUnexpectedEOF {
+            last = true
+        } else {
+            chunk.err = err
+        }
+
+        if len(data) == 0 && chunk.err == nil {
+            <-inflight
+            return
+        }
+
+        chunk.data = data
+
+        start.Offset += int64(len(data))
+        start.Line += bytes.Count(data, []byte{'\n'})
+
+        select {
+        case chunks <- chunk:
+        case <-done:
+            return
+        }
+
+        if last == true || chunk.err != nil {
+            return
+        }
+    }
+}
+
+// simpleObjectType returns true if T is SimpleObject or OrderedSimpleObject,
+// in which case objects are produced rather than decoded, and whether it's the
+// latter.
+func simpleObjectType[T any]() (isSimpleObject bool, ordered bool) {
+    var zero T
+
+    switch any(zero).(type) {
+    case SimpleObject:
+        return true, false
+    case OrderedSimpleObject:
+        return true, true
+    }
+
+    return false, false
+}
+
+// parseParallelChunk parses the documents in one chunk.
+func parseParallelChunk[T any](chunk parallelChunk, parserOptions []ParserOption) (items []parallelItem[T]) {
+    items = make([]parallelItem[T], 0)
+
+    if chunk.err != nil {
+        return append(items, parallelItem[T]{err: chunk.err, fatal: true})
+    }
+
+    isSimpleObject, ordered := simpleObjectType[T]()
+
+    options := make([]ParserOption, 0, len(parserOptions) + 2)
+    options = append(options, parserOptions...)
+    options = append(options, WithDocumentMode(DocumentModeLines))
+
+    if ordered == true {
+        options = append(options, WithOrderedObjects())
+    }
+
+    p := NewParser(bytes.NewReader(chunk.data), options...)
+
+    // Report the positions within the whole input.
+    p.records.line = chunk.start.Line
+    p.records.offset = chunk.start.Offset
+    p.records.lineStart = chunk.start.Offset
+
+    if isSimpleObject == false {
+        selector, err := ParseSelector("$")
+        if err != nil {
+            return append(items, parallelItem[T]{err: err, fatal: true})
+        }
+
+        err = p.startDecoding(selector, func(dec Decoder) error {
+            var value T
+            if err := dec.Decode(&value); err != nil {
+                if p.err != nil {
+                    return err
+                }
+
+                re := RecordError{
+                    Line: p.span.Start.Line,
+                    Offset: p.span.Start.Offset,
+                    Raw: append([]byte{}, bytes.TrimRight(p.records.buf, "\r\n")...),
+                    Err: err,
+                }
+
+                items = append(items, parallelItem[T]{err: re})
+                return nil
+            }
+
+            items = append(items, parallelItem[T]{value: value})
+            return nil
+        })
+
+        if err != nil {
+            return append(items, parallelItem[T]{err: err, fatal: true})
+        }
+    }
+
+    for {
+        token, err := p.Next()
+        if err == io.EOF {
+            break
+        } else if err != nil {
+            return append(items, parallelItem[T]{err: err, fatal: true})
+        }
+
+        switch t := token.Value().(type) {
+        case RecordError:
+            items = append(items, parallelItem[T]{err: t})
+        case SimpleObject, OrderedSimpleObject:
+            // Only the object at the root of the document.
+            if isSimpleObject == true && p.last.dc.Depth() == 0 {
+                items = append(items, parallelItem[T]{value: t.(T)})
+            }
+        }
+    }
+
+    return items
+}
+
+// elementChunk is a run of consecutive elements that were found by the
+// pre-scan.
+type elementChunk struct {
+    index int
+
+    // data is the input from the start of the first element to the end of
+    // the last one, with everything between the elements (e.g. the commas)
+    // replaced by whitespace so that positions can still be calculated.
+    data []byte
+
+    // start is where the first element starts and end is where the last one
+    // ends.
+    start Position
+    end Position
+
+    // locations has the location of every element in the chunk.
+    locations []tokenLocation
+
+    // err is an error that stopped the pre-scan after the elements in the
+    // chunk.
+    err error
+}
+
+// ParallelElements is like Elements, but the elements are decoded on several
+// goroutines. It's meant for a huge list of small values (e.g. a file that is
+// a single list of millions of records). If T is SimpleObject (or
+// OrderedSimpleObject), the SimpleObject of every element that is an object is
+// produced instead (and other elements are ignored).
+//
+// The input is pre-scanned only to find where every element starts and ends,
+// and chunks of consecutive elements are then tokenized and decoded by the
+// workers. The values are produced in index order unless
+// ParallelOptions.Unordered is set. At most two chunks per worker are held in
+// memory. ParallelOptions.ParserOptions are given to the parser of every
+// chunk.
+//
+// An element that can't be decoded into a T is yielded as an *ElementError
+// and iteration continues. Any other error is yielded once and iteration
+// stops. Breaking out of the loop stops all of the goroutines.
+func ParallelElements[T any](r io.Reader, path string, options ParallelOptions) iter.Seq2[T, error] {
+    return func(yield func(T, error) bool) {
+        var zero T
+
+        selector, err := ParseSelector(path + "[*]")
+        if err != nil {
+            yield(zero, err)
+            return
+        }
+
+        workers := options.Workers
+        if workers <= 0 {
+            workers = runtime.GOMAXPROCS(0)
+        }
+
+        chunkSize := options.ChunkSize
+        if chunkSize <= 0 {
+            chunkSize = defaultParallelChunkSize
+        }
+
+        runParallel(yield, workers, options.Unordered, func(chunks chan<- elementChunk, inflight chan struct{}, done <-chan struct{}) {
+            readElementChunks(r, selector, chunkSize, chunks, inflight, done)
+        }, func(chunk elementChunk) parallelResult[T] {
+            result := parallelResult[T]{
+                index: chunk.index,
+                items: parseElementChunk[T](chunk, options.ParserOptions),
+            }
+
+            return result
+        })
+    }
+}
+
+// readElementChunks finds the elements matched by the selector and sends them
+// to the workers in chunks. The elements are only skipped over, not tokenized.
+func readElementChunks(r io.Reader, selector *Selector, chunkSize int, chunks chan<- elementChunk, inflight chan struct{}, done <-chan struct{}) {
+    defer close(chunks)
+
+    index := 0
+    var chunk *elementChunk
+
+    // startChunk waits until another chunk is allowed. It returns false if we
+    // have to stop.
+    startChunk := func() bool {
+        select {
+        case inflight <- struct{}{}:
+        case <-done:
+            return false
+        }
+
+        chunk = &elementChunk{
+            index: index,
+            data: make([]byte, 0, chunkSize),
+            locations: make([]tokenLocation, 0),
+        }
+
+        index++
+
+        return true
+    }
+
+    // sendChunk sends the current chunk. It returns false if we have to stop.
+    sendChunk := func() bool {
+        select {
+        case chunks <- *chunk:
+        case <-done:
+            return false
+        }
+
+        chunk = nil
+
+        return true
+    }
+
+    p := NewParser(r)
+
+    err := p.DecodeAt(selector, func(dec Decoder) error {
+        vd := dec.(*valueDecoder)
+
+        // The wildcard would also match the members of an object.
+        if vd.location.element.IsIndex == false {
+            return nil
+        }
+
+        if chunk == nil && startChunk() == false {
+            return errStopElements
+        }
+
+        var raw json.RawMessage
+        if err := dec.Decode(&raw); err != nil {
+            return err
+        }
+
+        span := vd.p.span
+
+        if len(chunk.locations) == 0 {
+            chunk.start = span.Start
+        } else {
+            chunk.data = appendGap(chunk.data, chunk.end, span.Start)
+        }
+
+        chunk.data = append(chunk.data, raw...)
+        chunk.end = span.End
+
+        location := vd.location
+        location.span = span
+
+        chunk.locations = append(chunk.locations, location)
+
+        if len(chunk.data) >= chunkSize && sendChunk() == false {
+            return errStopElements
+        }
+
+        return nil
+    })
+
+    if errors.Is(err, errStopElements) == true {
+        return
+    }
+
+    if err != nil {
+        if chunk == nil && startChunk() == false {
+            return
+        }
+
+        chunk.err = err
+    }
+
+    if chunk != nil {
+        sendChunk()
+    }
+}
+
+// appendGap appends whitespace that spans from one position to the other
+// (with the same number of newlines) so that the positions after it are the
+// same as in the input.
+func appendGap(data []byte, from, to Position) []byte {
+    gap := int(to.Offset - from.Offset)
+
+    newlines := to.Line - from.Line
+    if newlines == 0 {
+        return append(data, bytes.Repeat([]byte{' '}, gap)...)
+    }
+
+    indent := to.Column - 1
+
+    data = append(data, bytes.Repeat([]byte{' '}, gap - newlines - indent)...)
+    data = append(data, bytes.Repeat([]byte{'\n'}, newlines)...)
+    data = append(data, bytes.Repeat([]byte{' '}, indent)...)
+
+    return data
+}
+
+// parseElementChunk decodes the elements in one chunk.
+func parseElementChunk[T any](chunk elementChunk, parserOptions []ParserOption) (items []parallelItem[T]) {
+    items = make([]parallelItem[T], 0, len(chunk.locations))
+
+    if len(chunk.locations) > 0 {
+        items = decodeElementChunk[T](chunk, parserOptions, items)
+        if len(items) > 0 && items[len(items) - 1].fatal == true {
+            return items
+        }
+    }
+
+    if chunk.err != nil {
+        items = append(items, parallelItem[T]{err: chunk.err, fatal: true})
+    }
+
+    return items
+}
+
+// decodeElementChunk parses the elements in the chunk as concatenated
+// documents.
+func decodeElementChunk[T any](chunk elementChunk, parserOptions []ParserOption, items []parallelItem[T]) []parallelItem[T] {
+    isSimpleObject, ordered := simpleObjectType[T]()
+
+    options := make([]ParserOption, 0, len(parserOptions) + 2)
+    options = append(options, parserOptions...)
+    options = append(options, WithDocumentMode(DocumentModeConcatenated))
+
+    if ordered == true {
+        options = append(options, WithOrderedObjects())
+    }
+
+    p := NewParser(bytes.NewReader(chunk.data), options...)
+
+    // Report the positions within the whole input.
+    p.tracker = newLineTrackerAt(bytes.NewReader(chunk.data), chunk.start)
+    p.source = p.newSource(p.tracker)
+
+    // document is the index of the current element in the chunk.
+    document := -1
+
+    if isSimpleObject == false {
+        selector, err := ParseSelector("$")
+        if err != nil {
+            return append(items, parallelItem[T]{err: err, fatal: true})
+        }
+
+        err = p.startDecoding(selector, func(dec Decoder) error {
+            var value T
+            if err := dec.Decode(&value); err != nil {
+                if p.err != nil {
+                    return err
+                }
+
+                location := chunk.locations[document]
+
+                ee := &ElementError{
+                    Index: location.element.Index,
+                    Path: location.Path(),
+                    Span: location.span,
+                    Err: err,
+                }
+
+                items = append(items, parallelItem[T]{err: ee})
+                return nil
+            }
+
+            items = append(items, parallelItem[T]{value: value})
+            return nil
+        })
+
+        if err != nil {
+            return append(items, parallelItem[T]{err: err, fatal: true})
+        }
+    }
+
+    for {
+        token, err := p.Next()
+        if err == io.EOF {
+            break
+        } else if err != nil {
+            return append(items, parallelItem[T]{err: err, fatal: true})
+        }
+
+        switch t := token.Value().(type) {
+        case DocumentStart:
+            document++
+        case SimpleObject, OrderedSimpleObject:
+            // Only the element itself.
+            if isSimpleObject == true && p.last.dc.Depth() == 0 {
+                items = append(items, parallelItem[T]{value: t.(T)})
+            }
+        }
+    }
+
+    return items
+}
@@ -0,0 +1,540 @@
+package jsonreader
+
+import (
+    "testing"
+    "strings"
+    "fmt"
+    "errors"
+    "runtime"
+    "time"
+    "sort"
+
+    "github.com/dsoprea/go-logging"
+)
+
+type testRecord struct {
+    Index int `json:"index"`
+    Name string `json:"name"`
+}
+
+// parallelTestData returns the given number of lines, each with its index. A
+// blank line follows every hundredth one.
+func parallelTestData(count int) string {
+    b := new(strings.Builder)
+
+    for i := 0; i < count; i++ {
+        fmt.Fprintf(b, "{\"index\": %d, \"name\": \"record%d\"}\n", i, i)
+
+        // Make sure that blank lines are ignored.
+        if i % 100 == 0 {
+            b.WriteString("\n")
+        }
+    }
+
+    return b.String()
+}
+
+func TestParallelLines(t *testing.T) {
+    data := parallelTestData(1000)
+
+    for _, native := range []bool{false, true} {
+        options := ParallelOptions{
+            Workers: 4,
+            ChunkSize: 100,
+        }
+
+        if native == false {
+            options.ParserOptions = []ParserOption{WithStandardDecoder()}
+        }
+
+        records := make([]testRecord, 0)
+        for record, err := range ParallelLines[testRecord](strings.NewReader(data), options) {
+            log.PanicIf(err)
+
+            records = append(records, record)
+        }
+
+        if len(records) != 1000 {
+            t.Fatalf("Record count not correct (native=%v): (%d)", native, len(records))
+        }
+
+        for i, record := range records {
+            if record.Index != i || record.Name != fmt.Sprintf("record%d", i) {
+                t.Fatalf("Record (%d) not correct (native=%v): %v", i, native, record)
+            }
+        }
+    }
+}
+
+func TestParallelLines_Unordered(t *testing.T) {
+    data := parallelTestData(1000)
+
+    options := ParallelOptions{
+        Workers: 4,
+        ChunkSize: 100,
+        Unordered: true,
+    }
+
+    indices := make([]int, 0)
+    for record, err := range ParallelLines[testRecord](strings.NewReader(data), options) {
+        log.PanicIf(err)
+
+        indices = append(indices, record.Index)
+    }
+
+    sort.Ints(indices)
+
+    if len(indices) != 1000 {
+        t.Fatalf("Record count not correct: (%d)", len(indices))
+    }
+
+    for i, index := range indices {
+        if index != i {
+            t.Fatalf("Record (%d) missing.", i)
+        }
+    }
+}
+
+func TestParallelLines_SimpleObject(t *testing.T) {
+    data := "{\"aa\": 1, \"bb\": {\"cc\": 2}}\n[1, 2]\n{\"aa\": 3}\n"
+
+    options := ParallelOptions{
+        Workers: 2,
+        ChunkSize: 1,
+        ParserOptions: []ParserOption{WithNumberMode(NumberModeExact)},
+    }
+
+    objects := make([]SimpleObject, 0)
+    for so, err := range ParallelLines[SimpleObject](strings.NewReader(data), options) {
+        log.PanicIf(err)
+
+        objects = append(objects, so)
+    }
+
+    if len(objects) != 2 {
+        t.Fatalf("Object count not correct: (%d)", len(objects))
+    } else if len(objects[0]) != 1 || objects[0]["aa"] != int64(1) {
+        t.Fatalf("First object not correct: %v", objects[0])
+    } else if objects[1]["aa"] != int64(3) {
+        t.Fatalf("Second object not correct: %v", objects[1])
+    }
+}
+
+func TestParallelLines_DecodeError(t *testing.T) {
+    data := "{\"index\": 0}\n{\"index\": \"one\"}\n{\"index\": 2}\n"
+
+    options := ParallelOptions{
+        Workers: 2,
+        ChunkSize: 1,
+    }
+
+    records := make([]testRecord, 0)
+    recordErrors := make([]RecordError, 0)
+
+    for record, err := range ParallelLines[testRecord](strings.NewReader(data), options) {
+        if err != nil {
+            var re RecordError
+            if errors.As(err, &re) == false {
+                t.Fatalf("Expected a RecordError: %v", err)
+            }
+
+            recordErrors = append(recordErrors, re)
+            continue
+        }
+
+        records = append(records, record)
+    }
+
+    if len(records) != 2 || records[1].Index != 2 {
+        t.Fatalf("Records not correct: %v", records)
+    } else if len(recordErrors) != 1 || recordErrors[0].Line != 2 || string(recordErrors[0].Raw) != `{"index": "one"}` {
+        t.Fatalf("Record errors not correct: %v", recordErrors)
+    }
+}
+
+func TestParallelLines_SyntaxError(t *testing.T) {
+    data := parallelTestData(50) + "{\"index\": \n" + parallelTestData(50)
+
+    options := ParallelOptions{
+        Workers: 4,
+        ChunkSize: 64,
+    }
+
+    count := 0
+    var lastErr error
+
+    for _, err := range ParallelLines[testRecord](strings.NewReader(data), options) {
+        if err != nil {
+            lastErr = err
+            continue
+        }
+
+        count++
+    }
+
+    var se *SyntaxError
+    if count != 50 {
+        t.Fatalf("Expected the records before the error: (%d)", count)
+    } else if errors.As(lastErr, &se) == false {
+        t.Fatalf("Expected a SyntaxError: %v", lastErr)
+    } else if se.Position.Line != 52 {
+        t.Fatalf("Error line not correct: %v", se.Position)
+    }
+}
+
+func TestParallelLines_RecordErrors(t *testing.T) {
+    data := parallelTestData(50) + "{\"index\": \n" + parallelTestData(50)
+
+    options := ParallelOptions{
+        Workers: 4,
+        ChunkSize: 64,
+        ParserOptions: []ParserOption{WithRecordErrors()},
+    }
+
+    count := 0
+    recordErrors := make([]RecordError, 0)
+
+    for _, err := range ParallelLines[testRecord](strings.NewReader(data), options) {
+        if err != nil {
+            var re RecordError
+            if errors.As(err, &re) == false {
+                t.Fatalf("Expected a RecordError: %v", err)
+            }
+
+            recordErrors = append(recordErrors, re)
+            continue
+        }
+
+        count++
+    }
+
+    if count != 100 {
+        t.Fatalf("Record count not correct: (%d)", count)
+    } else if len(recordErrors) != 1 || recordErrors[0].Line != 52 || strings.HasPrefix(recordErrors[0].Err.Error(), "line 52, column 10: ") == false {
+        t.Fatalf("Record errors not correct: %v", recordErrors)
+    }
+}
+
+func TestParallelLines_Break(t *testing.T) {
+    before := runtime.NumGoroutine()
+
+    data := parallelTestData(1000)
+
+    options := ParallelOptions{
+        Workers: 4,
+        ChunkSize: 100,
+    }
+
+    for i := 0; i < 10; i++ {
+        for _, err := range ParallelLines[testRecord](strings.NewReader(data), options) {
+            log.PanicIf(err)
+            break
+        }
+    }
+
+    deadline := time.Now().Add(time.Second)
+    for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+        time.Sleep(time.Millisecond * 10)
+    }
+
+    if runtime.NumGoroutine() > before {
+        t.Fatalf("Goroutines were leaked: (%d) > (%d)", runtime.NumGoroutine(), before)
+    }
+}
+
+// parallelElementsTestData returns an object with a list of the given number
+// of records at "$.records", with one record per line.
+func parallelElementsTestData(count int) string {
+    b := new(strings.Builder)
+
+    b.WriteString("{\n  \"name\": \"test\",\n  \"records\": [\n")
+
+    for i := 0; i < count; i++ {
+        if i > 0 {
+            b.WriteString(",\n")
+        }
+
+        fmt.Fprintf(b, "    {\"index\": %d, \"name\": \"record%d\"}", i, i)
+    }
+
+    b.WriteString("\n  ]\n}\n")
+
+    return b.String()
+}
+
+func TestParallelElements(t *testing.T) {
+    data := parallelElementsTestData(1000)
+
+    for _, native := range []bool{false, true} {
+        options := ParallelOptions{
+            Workers: 4,
+            ChunkSize: 100,
+        }
+
+        if native == false {
+            options.ParserOptions = []ParserOption{WithStandardDecoder()}
+        }
+
+        records := make([]testRecord, 0)
+        for record, err := range ParallelElements[testRecord](strings.NewReader(data), "$.records", options) {
+            log.PanicIf(err)
+
+            records = append(records, record)
+        }
+
+        if len(records) != 1000 {
+            t.Fatalf("Record count not correct (native=%v): (%d)", native, len(records))
+        }
+
+        for i, record := range records {
+            if record.Index != i || record.Name != fmt.Sprintf("record%d", i) {
+                t.Fatalf("Record (%d) not correct (native=%v): %v", i, native, record)
+            }
+        }
+    }
+}
+
+func TestParallelElements_LargeInput(t *testing.T) {
+    // The pre-scan has to handle elements that cross the end of its buffer.
+    b := new(strings.Builder)
+    b.WriteString("[")
+
+    for i := 0; i < 50000; i++ {
+        if i > 0 {
+            b.WriteString(",")
+        }
+
+        fmt.Fprintf(b, "%d", i)
+    }
+
+    b.WriteString("]")
+
+    if b.Len() <= 64 * 1024 {
+        t.Fatalf("Document isn't larger than the buffer: (%d)", b.Len())
+    }
+
+    options := ParallelOptions{
+        Workers: 4,
+        ChunkSize: 4096,
+    }
+
+    count := 0
+    for value, err := range ParallelElements[int](strings.NewReader(b.String()), "$", options) {
+        log.PanicIf(err)
+
+        if value != count {
+            t.Fatalf("Value (%d) not correct: (%d)", count, value)
+        }
+
+        count++
+    }
+
+    if count != 50000 {
+        t.Fatalf("Value count not correct: (%d)", count)
+    }
+
+    // Same for records that are larger than the buffer on their own.
+    data := "[{\"index\": 0, \"name\": \"" + strings.Repeat("x", 100000) + "\"}, {\"index\": 1, \"name\": \"y\"}]"
+
+    records := make([]testRecord, 0)
+    for record, err := range ParallelElements[testRecord](strings.NewReader(data), "$", options) {
+        log.PanicIf(err)
+
+        records = append(records, record)
+    }
+
+    if len(records) != 2 || len(records[0].Name) != 100000 || records[1].Name != "y" {
+        t.Fatalf("Records not correct: (%d)", len(records))
+    }
+}
+
+func TestParallelElements_Root(t *testing.T) {
+    data := "[1, 2,\n 3, [4, 5], 6]"
+
+    options := ParallelOptions{
+        Workers: 2,
+        ChunkSize: 1,
+    }
+
+    values := make([]interface{}, 0)
+    for value, err := range ParallelElements[interface{}](strings.NewReader(data), "$", options) {
+        log.PanicIf(err)
+
+        values = append(values, value)
+    }
+
+    if fmt.Sprintf("%v", values) != "[1 2 3 [4 5] 6]" {
+        t.Fatalf("Values not correct: %v", values)
+    }
+}
+
+func TestParallelElements_Unordered(t *testing.T) {
+    data := parallelElementsTestData(1000)
+
+    options := ParallelOptions{
+        Workers: 4,
+        ChunkSize: 100,
+        Unordered: true,
+    }
+
+    indices := make([]int, 0)
+    for record, err := range ParallelElements[testRecord](strings.NewReader(data), "$.records", options) {
+        log.PanicIf(err)
+
+        indices = append(indices, record.Index)
+    }
+
+    sort.Ints(indices)
+
+    if len(indices) != 1000 {
+        t.Fatalf("Record count not correct: (%d)", len(indices))
+    }
+
+    for i, index := range indices {
+        if index != i {
+            t.Fatalf("Record (%d) missing.", i)
+        }
+    }
+}
+
+func TestParallelElements_SimpleObject(t *testing.T) {
+    data := "[{\"aa\": 1, \"bb\": {\"cc\": 2}}, [1, 2], {\"aa\": 3}]"
+
+    options := ParallelOptions{
+        Workers: 2,
+        ChunkSize: 1,
+        ParserOptions: []ParserOption{WithNumberMode(NumberModeExact)},
+    }
+
+    objects := make([]SimpleObject, 0)
+    for so, err := range ParallelElements[SimpleObject](strings.NewReader(data), "$", options) {
+        log.PanicIf(err)
+
+        objects = append(objects, so)
+    }
+
+    if len(objects) != 2 {
+        t.Fatalf("Object count not correct: (%d)", len(objects))
+    } else if len(objects[0]) != 1 || objects[0]["aa"] != int64(1) {
+        t.Fatalf("First object not correct: %v", objects[0])
+    } else if objects[1]["aa"] != int64(3) {
+        t.Fatalf("Second object not correct: %v", objects[1])
+    }
+}
+
+func TestParallelElements_DecodeError(t *testing.T) {
+    data := "{\"records\": [\n  {\"index\": 0},\n  {\"index\": \"one\"},\n  {\"index\": 2}\n]}"
+
+    options := ParallelOptions{
+        Workers: 2,
+        ChunkSize: 20,
+    }
+
+    records := make([]testRecord, 0)
+    elementErrors := make([]*ElementError, 0)
+
+    for record, err := range ParallelElements[testRecord](strings.NewReader(data), "$.records", options) {
+        if err != nil {
+            var ee *ElementError
+            if errors.As(err, &ee) == false {
+                t.Fatalf("Expected an ElementError: %v", err)
+            }
+
+            elementErrors = append(elementErrors, ee)
+            continue
+        }
+
+        records = append(records, record)
+    }
+
+    if len(records) != 2 || records[1].Index != 2 {
+        t.Fatalf("Records not correct: %v", records)
+    } else if len(elementErrors) != 1 {
+        t.Fatalf("Element errors not correct: %v", elementErrors)
+    }
+
+    ee := elementErrors[0]
+    if ee.Index != 1 || ee.Path.String() != "$.records[1]" {
+        t.Fatalf("Element not correct: (%d) [%s]", ee.Index, ee.Path)
+    } else if ee.Span.Start != (Position{Offset: 32, Line: 3, Column: 3}) || ee.Span.End != (Position{Offset: 48, Line: 3, Column: 19}) {
+        t.Fatalf("Span not correct: %v", ee.Span)
+    }
+}
+
+func TestParallelElements_SyntaxError(t *testing.T) {
+    data := strings.Replace(parallelElementsTestData(100), "\"index\": 50,", "\"index\": 50 50,", 1)
+
+    options := ParallelOptions{
+        Workers: 4,
+        ChunkSize: 64,
+    }
+
+    count := 0
+    var lastErr error
+
+    for _, err := range ParallelElements[testRecord](strings.NewReader(data), "$.records", options) {
+        if err != nil {
+            lastErr = err
+            continue
+        }
+
+        count++
+    }
+
+    var se *SyntaxError
+    if count != 50 {
+        t.Fatalf("Expected the records before the error: (%d)", count)
+    } else if errors.As(lastErr, &se) == false {
+        t.Fatalf("Expected a SyntaxError: %v", lastErr)
+    } else if se.Position.Line != 54 || se.Position.Column != 18 {
+        t.Fatalf("Error position not correct: %v", se.Position)
+    }
+}
+
+func TestParallelElements_Truncated(t *testing.T) {
+    data := parallelElementsTestData(100)
+    data = data[:len(data) / 2]
+
+    options := ParallelOptions{
+        Workers: 4,
+        ChunkSize: 64,
+    }
+
+    var lastErr error
+    for _, err := range ParallelElements[testRecord](strings.NewReader(data), "$.records", options) {
+        if err != nil {
+            lastErr = err
+        }
+    }
+
+    if errors.Is(lastErr, ErrSyntax) == false {
+        t.Fatalf("Expected a syntax error: %v", lastErr)
+    }
+}
+
+func TestParallelElements_Break(t *testing.T) {
+    before := runtime.NumGoroutine()
+
+    data := parallelElementsTestData(1000)
+
+    options := ParallelOptions{
+        Workers: 4,
+        ChunkSize: 100,
+    }
+
+    for i := 0; i < 10; i++ {
+        for _, err := range ParallelElements[testRecord](strings.NewReader(data), "$.records", options) {
+            log.PanicIf(err)
+            break
+        }
+    }
+
+    deadline := time.Now().Add(time.Second)
+    for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+        time.Sleep(time.Millisecond * 10)
+    }
+
+    if runtime.NumGoroutine() > before {
+        t.Fatalf("Goroutines were leaked: (%d) > (%d)", runtime.NumGoroutine(), before)
+    }
+}
@@ -0,0 +1,131 @@
+package jsonreader
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+)
+
+// PathElement is one step from a container into one of its children.
+type PathElement struct {
+    // Key is the object key. It's only relevant if IsIndex is false.
+    Key string
+
+    // Index is the list index. It's only relevant if IsIndex is true.
+    Index int
+
+    IsIndex bool
+}
+
+// isIdentifier returns true if the key can be written in JSONPath dot-notation.
+func isIdentifier(key string) bool {
+    if key == "" {
+        return false
+    }
+
+    for i, r := range key {
+        if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+            continue
+        } else if i > 0 && r >= '0' && r <= '9' {
+            continue
+        }
+
+        return false
+    }
+
+    return true
+}
+
+// String returns the element in JSONPath notation (e.g. ".key", "['a key']",
+// or "[3]").
+func (pe PathElement) String() string {
+    if pe.IsIndex == true {
+        return fmt.Sprintf("[%d]", pe.Index)
+    } else if isIdentifier(pe.Key) == true {
+        return "." + pe.Key
+    }
+
+    quoted := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(pe.Key)
+    return "['" + quoted + "']"
+}
+
+// Path is the location of a value relative to the root value. The root value
+// itself has an empty path.
+type Path []PathElement
+
+// String returns the path as a JSONPath expression (e.g.
+// "$.locations[42].latitudeE7").
+func (p Path) String() string {
+    b := strings.Builder{}
+    b.WriteString("$")
+
+    for _, pe := range p {
+        b.WriteString(pe.String())
+    }
+
+    return b.String()
+}
+
+// Pointer returns the path as an RFC 6901 JSON Pointer (e.g.
+// "/locations/42/latitudeE7"). The root is the empty string.
+func (p Path) Pointer() string {
+    b := strings.Builder{}
+
+    escaper := strings.NewReplacer("~", "~0", "/", "~1")
+    for _, pe := range p {
+        b.WriteString("/")
+
+        if pe.IsIndex == true {
+            b.WriteString(strconv.Itoa(pe.Index))
+        } else {
+            b.WriteString(escaper.Replace(pe.Key))
+        }
+    }
+
+    return b.String()
+}
+
+// tokenLocation records where a token was found without having to allocate a
+// Path for every token.
+type tokenLocation struct {
+    // dc is the container that the token was found in (or nil for the top
+    // level).
+    dc *delimiterChain
+
+    // element is the position of the token within that container.
+    element PathElement
+    hasElement bool
+
+    // span is where the token is in the input.
+    span Span
+
+    // children is how many members or elements an object or list had. It's
+    // only set for ObjectClose and ListClose.
+    children int
+}
+
+// Path builds the full path of the token.
+func (tl tokenLocation) Path() Path {
+    // The root container is at a depth of one and has no path element, so
+    // every container below it contributes one.
+    len_ := 0
+    if depth := tl.dc.Depth(); depth > 1 {
+        len_ = depth - 1
+    }
+
+    if tl.hasElement == true {
+        len_++
+    }
+
+    path := make(Path, len_)
+
+    for dc := tl.dc; dc != nil && dc.depth > 1; dc = dc.parent {
+        path[dc.depth - 2] = dc.element
+    }
+
+    if tl.hasElement == true {
+        path[len_ - 1] = tl.element
+    }
+
+    return path
+}
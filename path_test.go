@@ -0,0 +1,85 @@
+package jsonreader
+
+import (
+    "testing"
+    "strings"
+
+    "github.com/dsoprea/go-logging"
+)
+
+func TestPath_String(t *testing.T) {
+    path := Path{
+        PathElement{Key: "locations"},
+        PathElement{Index: 42, IsIndex: true},
+        PathElement{Key: "latitudeE7"},
+        PathElement{Key: "a 'b'"},
+    }
+
+    if path.String() != `$.locations[42].latitudeE7['a \'b\'']` {
+        t.Fatalf("JSONPath not correct: [%s]", path.String())
+    }
+}
+
+func TestPath_String_Root(t *testing.T) {
+    if (Path{}).String() != "$" {
+        t.Fatalf("Root JSONPath not correct.")
+    }
+}
+
+func TestPath_Pointer(t *testing.T) {
+    path := Path{
+        PathElement{Key: "locations"},
+        PathElement{Index: 42, IsIndex: true},
+        PathElement{Key: "a/b~c"},
+    }
+
+    if path.Pointer() != "/locations/42/a~1b~0c" {
+        t.Fatalf("Pointer not correct: [%s]", path.Pointer())
+    } else if (Path{}).Pointer() != "" {
+        t.Fatalf("Root pointer not correct.")
+    }
+}
+
+func TestParser_Path(t *testing.T) {
+    r := strings.NewReader(`{"aa": [1, {"bb": "cc", "dd": [true]}], "ee": null}`)
+
+    p := NewParser(r)
+
+    actual := make([]string, 0)
+    for token, err := range p.All() {
+        log.PanicIf(err)
+
+        actual = append(actual, flattenToken(token) + " " + p.Path().String())
+    }
+
+    expected := []string{
+        "/OBJECTOPEN $",
+        ":aa $.aa",
+        "/LISTOPEN $.aa",
+        "#FLOAT64=1.000000 $.aa[0]",
+        "/OBJECTOPEN $.aa[1]",
+        ":bb $.aa[1].bb",
+        "[bb] S cc $.aa[1].bb",
+        ":dd $.aa[1].dd",
+        "/LISTOPEN $.aa[1].dd",
+        "#BOOL=true $.aa[1].dd[0]",
+        "/LISTCLOSE $.aa[1].dd",
+        "/OBJECTCLOSE $.aa[1]",
+        "@bb:cc $.aa[1]",
+        "/LISTCLOSE $.aa",
+        ":ee $.ee",
+        "[ee] N $.ee",
+        "/OBJECTCLOSE $",
+        "@ee:<nil> $",
+    }
+
+    if len(actual) != len(expected) {
+        t.Fatalf("Token count not correct: %v", actual)
+    }
+
+    for i, entry := range actual {
+        if expected[i] != entry {
+            t.Fatalf("Item (%d) value [%s] should be [%s].", i, entry, expected[i])
+        }
+    }
+}
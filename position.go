@@ -0,0 +1,260 @@
+package jsonreader
+
+import (
+    "fmt"
+    "io"
+    "bytes"
+    "errors"
+
+    "encoding/json"
+)
+
+// Position is a location in the input.
+type Position struct {
+    // Offset is the number of bytes before this position.
+    Offset int64
+
+    // Line and Column start at one. Column counts bytes, not characters. They
+    // are zero if the position couldn't be determined.
+    Line int
+    Column int
+}
+
+func (pos Position) String() string {
+    return fmt.Sprintf("line %d, column %d (offset %d)", pos.Line, pos.Column, pos.Offset)
+}
+
+// Span is where a token starts and ends in the input. End is the position just
+// after the last byte of the token.
+type Span struct {
+    Start Position
+    End Position
+}
+
+// lineTracker counts the lines in the data that passes through it so that
+// offsets can be converted to lines and columns. Only the newlines that have
+// been read but not yet passed are kept.
+type lineTracker struct {
+    r io.Reader
+
+    // base is the offset of the first byte of r in the whole input.
+    base int64
+
+    // read is the number of bytes read so far.
+    read int64
+
+    // newlines are the offsets of the newlines that we've read but that are
+    // after the last position that was converted.
+    newlines []int64
+    newlinesIndex int
+
+    // line is the line that the last converted position was on and
+    // lineStart is the offset of its first byte.
+    line int
+    lineStart int64
+}
+
+func newLineTracker(r io.Reader) *lineTracker {
+    return &lineTracker{
+        r: r,
+        newlines: make([]int64, 0),
+        line: 1,
+    }
+}
+
+// newLineTrackerAt is like newLineTracker for a part of a larger input that
+// starts at the given position. The offsets given to position() are relative
+// to the start of the part.
+func newLineTrackerAt(r io.Reader, start Position) *lineTracker {
+    return &lineTracker{
+        r: r,
+        base: start.Offset,
+        read: start.Offset,
+        newlines: make([]int64, 0),
+        line: start.Line,
+        lineStart: start.Offset - int64(start.Column - 1),
+    }
+}
+
+func (lt *lineTracker) Read(b []byte) (n int, err error) {
+    n, err = lt.r.Read(b)
+
+    if lt.newlinesIndex == len(lt.newlines) {
+        lt.newlines = lt.newlines[:0]
+        lt.newlinesIndex = 0
+    }
+
+    for i := 0; i < n; {
+        j := bytes.IndexByte(b[i:n], '\n')
+        if j == -1 {
+            break
+        }
+
+        lt.newlines = append(lt.newlines, lt.read + int64(i + j))
+        i += j + 1
+    }
+
+    lt.read += int64(n)
+
+    return n, err
+}
+
+// position converts an offset. The offsets have to be converted in order. An
+// earlier offset than the last one converted only gets a line and column if
+// it's on the same line.
+func (lt *lineTracker) position(offset int64) Position {
+    offset += lt.base
+
+    for lt.newlinesIndex < len(lt.newlines) && lt.newlines[lt.newlinesIndex] < offset {
+        lt.line++
+        lt.lineStart = lt.newlines[lt.newlinesIndex] + 1
+        lt.newlinesIndex++
+    }
+
+    if offset < lt.lineStart {
+        return Position{Offset: offset}
+    }
+
+    return Position{
+        Offset: offset,
+        Line: lt.line,
+        Column: int(offset - lt.lineStart) + 1,
+    }
+}
+
+// isSeparator returns true for the bytes that json.Decoder skips between
+// values.
+func isSeparator(c byte) bool {
+    return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',' || c == ':'
+}
+
+// nextValueOffset returns the offset of the first byte of the next value by
+// looking past the whitespace and separators that json.Decoder has already
+// buffered. If the buffered data runs out first, the offset after it is
+// returned and exact is false. The scratch buffer is only used for reading.
+func nextValueOffset(d *json.Decoder, scratch []byte) (offset int64, exact bool) {
+    offset = d.InputOffset()
+    buffered := d.Buffered()
+
+    for {
+        n, err := buffered.Read(scratch)
+
+        for _, c := range scratch[:n] {
+            if isSeparator(c) == false {
+                return offset, true
+            }
+
+            offset++
+        }
+
+        if err != nil || n == 0 {
+            return offset, false
+        }
+    }
+}
+
+// startReader is what json.Decoder reads through. It finds the first byte of a
+// value when the whitespace before it continues past what the decoder had
+// already buffered (see nextValueOffset).
+type startReader struct {
+    r io.Reader
+
+    // read is the number of bytes read so far.
+    read int64
+
+    // seeking indicates that the next byte that isn't whitespace or a
+    // separator is the start of a value. found is its offset.
+    seeking bool
+    found int64
+}
+
+func (sr *startReader) Read(b []byte) (n int, err error) {
+    n, err = sr.r.Read(b)
+
+    if sr.seeking == true {
+        for i, c := range b[:n] {
+            if isSeparator(c) == false {
+                sr.found = sr.read + int64(i)
+                sr.seeking = false
+
+                break
+            }
+        }
+    }
+
+    sr.read += int64(n)
+
+    return n, err
+}
+
+// Span returns where the token most recently returned by Next() (or All())
+// starts and ends. For a SimpleObject or Object this covers the whole object
+// or list.
+func (p *Parser) Span() Span {
+    return p.last.span
+}
+
+// markToken records the span of the token or value that the source just
+// produced.
+func (p *Parser) markToken() {
+    p.span.Start = p.tracker.position(p.source.TokenStart())
+    p.span.End = p.tracker.position(p.source.InputOffset())
+}
+
+// sourceError converts the errors that mean that the input is invalid or ends
+// early to a *SyntaxError. Other errors are returned as they are.
+func (p *Parser) sourceError(err error) error {
+    se := &SyntaxError{
+        Err: err,
+    }
+
+    offset := p.source.InputOffset()
+
+    var jse *json.SyntaxError
+    var scanErr *scanError
+
+    if errors.As(err, &jse) == true {
+        // The offset is after the byte that was invalid.
+        offset = jse.Offset
+        if offset > 0 {
+            offset--
+        }
+
+        se.Expected, se.Actual = describeDecoderError(jse)
+    } else if errors.As(err, &scanErr) == true {
+        offset = scanErr.offset
+        se.Expected = scanErr.expected
+        se.Actual = scanErr.actual
+    } else if err == io.EOF || err == io.ErrUnexpectedEOF {
+        // We only get here if we expected more.
+        se.Err = io.ErrUnexpectedEOF
+        se.Actual = "EOF"
+    } else {
+        return err
+    }
+
+    if se.isUnbalanced() == true {
+        se.Err = ErrUnbalancedDelimiters
+    }
+
+    se.Position = p.tracker.position(offset)
+    se.Path = p.currentLocation().Path()
+
+    return se
+}
+
+// currentLocation returns where we currently are, without a path element if
+// we're waiting for the next key of an object.
+func (p *Parser) currentLocation() tokenLocation {
+    current := p.currentFrame()
+
+    location := tokenLocation{
+        dc: current.dc,
+    }
+
+    if current.dc.Delimiter() != '{' || current.i % 2 == 1 {
+        location.element, location.hasElement = current.cursor()
+    }
+
+    return location
+}
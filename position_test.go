@@ -0,0 +1,187 @@
+package jsonreader
+
+import (
+    "testing"
+    "strings"
+    "fmt"
+    "reflect"
+    "io"
+    "errors"
+    "bytes"
+    "math/rand"
+
+    "github.com/dsoprea/go-logging"
+)
+
+// flattenSpans returns every token along with where it starts and ends.
+func flattenSpans(p *Parser) (ts []string, err error) {
+    defer func() {
+        if state := recover(); state != nil {
+            err = log.Wrap(state.(error))
+        }
+    }()
+
+    ts = make([]string, 0)
+    for {
+        token, err := p.Next()
+        if err == io.EOF {
+            break
+        }
+
+        log.PanicIf(err)
+
+        span := p.Span()
+        ts = append(ts, fmt.Sprintf("%s %d:%d-%d:%d (%d-%d)", flattenToken(token), span.Start.Line, span.Start.Column, span.End.Line, span.End.Column, span.Start.Offset, span.End.Offset))
+    }
+
+    return ts, nil
+}
+
+func TestParser_Span(t *testing.T) {
+    document := "{\n  \"aa\": [1, \"two\"],\n  \"bb\": {\"cc\": null}\n}\n"
+
+    expected := []string{
+        "/OBJECTOPEN 1:1-1:2 (0-1)",
+        ":aa 2:3-2:7 (4-8)",
+        "/LISTOPEN 2:9-2:10 (10-11)",
+        "#FLOAT64=1.000000 2:10-2:11 (11-12)",
+        "#STRING=two 2:13-2:18 (14-19)",
+        "/LISTCLOSE 2:18-2:19 (19-20)",
+        ":bb 3:3-3:7 (24-28)",
+        "/OBJECTOPEN 3:9-3:10 (30-31)",
+        ":cc 3:10-3:14 (31-35)",
+        "[cc] N 3:16-3:20 (37-41)",
+        "/OBJECTCLOSE 3:20-3:21 (41-42)",
+        "@cc:<nil> 3:9-3:21 (30-42)",
+        "/OBJECTCLOSE 4:1-4:2 (43-44)",
+        "@ 1:1-4:2 (0-44)",
+    }
+
+    for _, native := range []bool{false, true} {
+        options := make([]ParserOption, 0)
+        if native == false {
+            options = append(options, WithStandardDecoder())
+        }
+
+        p := NewParser(strings.NewReader(document), options...)
+
+        ts, err := flattenSpans(p)
+        log.PanicIf(err)
+
+        if reflect.DeepEqual(ts, expected) == false {
+            t.Fatalf("Spans not correct (native=%v):\nACTUAL: %v\nEXPECTED: %v", native, ts, expected)
+        }
+    }
+}
+
+// chunkReader returns the data in chunks of random sizes.
+type chunkReader struct {
+    data []byte
+    random *rand.Rand
+}
+
+func (cr *chunkReader) Read(b []byte) (n int, err error) {
+    if len(cr.data) == 0 {
+        return 0, io.EOF
+    }
+
+    n = min(cr.random.Intn(8) + 1, len(b))
+    n = copy(b[:n], cr.data)
+    cr.data = cr.data[n:]
+
+    return n, nil
+}
+
+func TestParser_Span_Whitespace(t *testing.T) {
+    random := rand.New(rand.NewSource(1))
+
+    whitespace := func() string {
+        return strings.Repeat(" \n\t"[random.Intn(3):][:1], random.Intn(6))
+    }
+
+    for i := 0; i < 200; i++ {
+        b := new(strings.Builder)
+        b.WriteString(whitespace() + "{" + whitespace())
+
+        for j := 0; j < 10; j++ {
+            if j > 0 {
+                b.WriteString("," + whitespace())
+            }
+
+            fmt.Fprintf(b, `"k%d"%s:%s[%s1%s,%s"x"%s]%s`, j, whitespace(), whitespace(), whitespace(), whitespace(), whitespace(), whitespace(), whitespace())
+        }
+
+        b.WriteString("}" + whitespace())
+
+        document := []byte(b.String())
+
+        // The native scanner is always exact.
+        expected, err := flattenSpans(NewParser(bytes.NewReader(document), WithNativeScanner()))
+        log.PanicIf(err)
+
+        cr := &chunkReader{
+            data: document,
+            random: random,
+        }
+
+        actual, err := flattenSpans(NewParser(cr, WithStandardDecoder()))
+        log.PanicIf(err)
+
+        if reflect.DeepEqual(actual, expected) == false {
+            t.Fatalf("Spans not correct for [%q]:\nACTUAL: %v\nEXPECTED: %v", document, actual, expected)
+        }
+    }
+}
+
+func TestParser_SyntaxError_Position(t *testing.T) {
+    document := "[\n  1,\n  2 3\n]"
+
+    for _, native := range []bool{false, true} {
+        options := make([]ParserOption, 0)
+        if native == false {
+            options = append(options, WithStandardDecoder())
+        }
+
+        p := NewParser(strings.NewReader(document), options...)
+
+        _, err := flattenNext(p)
+        if err == nil {
+            t.Fatalf("Expected error (native=%v).", native)
+        } else if strings.HasPrefix(err.Error(), "line 3, column 5: ") == false {
+            t.Fatalf("Error position not correct (native=%v): [%s]", native, err.Error())
+        }
+    }
+}
+
+func TestParser_SyntaxError_Truncated(t *testing.T) {
+    p := NewParser(strings.NewReader("{\"aa\": [1,\n2"))
+
+    err := nextError(p)
+    if errors.Is(err, io.ErrUnexpectedEOF) == false {
+        t.Fatalf("Expected unexpected-EOF: %v", err)
+    } else if strings.HasPrefix(err.Error(), "line 2, column 2: ") == false {
+        t.Fatalf("Error position not correct: [%s]", err.Error())
+    }
+}
+
+func TestLineTracker(t *testing.T) {
+    lt := newLineTracker(&byteReader{data: []byte("ab\n\ncd\ne")})
+
+    _, err := io.ReadAll(lt)
+    log.PanicIf(err)
+
+    expected := map[int64]Position{
+        0: Position{Offset: 0, Line: 1, Column: 1},
+        2: Position{Offset: 2, Line: 1, Column: 3},
+        3: Position{Offset: 3, Line: 2, Column: 1},
+        5: Position{Offset: 5, Line: 3, Column: 2},
+        8: Position{Offset: 8, Line: 4, Column: 2},
+    }
+
+    for _, offset := range []int64{0, 2, 3, 5, 8} {
+        pos := lt.position(offset)
+        if pos != expected[offset] {
+            t.Fatalf("Position for offset (%d) not correct: %v", offset, pos)
+        }
+    }
+}
@@ -0,0 +1,178 @@
+package jsonreader
+
+import (
+    "bytes"
+    "fmt"
+    "io"
+    "strings"
+
+    "encoding/json"
+)
+
+// RecordError is produced in place of a document that isn't valid when
+// WithRecordErrors is used. None of the tokens of that document are produced.
+type RecordError struct {
+    // Line and Offset are where the document starts.
+    Line int
+    Offset int64
+
+    // Raw is the text of the document (without the line ending).
+    Raw []byte
+
+    // Err is the *SyntaxError describing the problem or, for a document that
+    // is valid JSON, the error from converting a number that it has (e.g. one
+    // that's out of range for a float64). From ParallelLines, it may also be
+    // the error from decoding the document.
+    Err error
+}
+
+func (re RecordError) Error() string {
+    return fmt.Sprintf("document on line (%d) is not valid: %s", re.Line, re.Err.Error())
+}
+
+func (re RecordError) Unwrap() error {
+    return re.Err
+}
+
+// WithRecordErrors checks every document before producing any of its tokens.
+// A document that isn't valid JSON (or that has a number that can't be
+// converted) is reported as a RecordError token and parsing continues with the
+// next document instead of failing. This only
+// applies to DocumentModeLines and DocumentModeSequence, where the next
+// document can always be found. Each document is read twice.
+func WithRecordErrors() ParserOption {
+    return func(p *Parser) {
+        p.recordErrors = true
+    }
+}
+
+// RecordErrorCount returns the number of RecordError tokens produced so far.
+func (p *Parser) RecordErrorCount() int {
+    return p.recordErrorCount
+}
+
+// checkRecord returns a RecordError if the record isn't a single valid JSON
+// value or has a number that can't be converted in the given mode.
+func checkRecord(record []byte, start Position, mode NumberMode) (re *RecordError) {
+    // discardValue doesn't build anything, but the whole value is validated
+    // first.
+    err := json.Unmarshal(record, &discardValue{})
+    if err == nil {
+        err := checkNumbers(record, mode)
+        if err == nil {
+            return nil
+        }
+
+        re = &RecordError{
+            Line: start.Line,
+            Offset: start.Offset,
+            Raw: append([]byte{}, bytes.TrimRight(record, "\r\n")...),
+            Err: err,
+        }
+
+        return re
+    }
+
+    se := &SyntaxError{
+        Err: err,
+    }
+
+    offset := int64(len(record))
+
+    jse, ok := err.(*json.SyntaxError)
+    if ok == true && jse.Offset > 0 && jse.Offset <= offset && strings.HasPrefix(jse.Error(), "invalid character ") == true {
+        // The offset is after the byte that was invalid.
+        offset = jse.Offset - 1
+        se.Expected, se.Actual = describeDecoderError(jse)
+    } else {
+        // The only other syntax error is "unexpected end of JSON input".
+        // Put it right after the last thing in the document.
+        offset = int64(len(bytes.TrimRight(record, " \t\r\n")))
+
+        se.Err = io.ErrUnexpectedEOF
+        se.Actual = "EOF"
+    }
+
+    if se.isUnbalanced() == true {
+        se.Err = ErrUnbalancedDelimiters
+    }
+
+    se.Position = positionInRecord(record, start, offset)
+
+    raw := bytes.TrimRight(record, "\r\n")
+
+    re = &RecordError{
+        Line: start.Line,
+        Offset: start.Offset,
+        Raw: append([]byte{}, raw...),
+        Err: se,
+    }
+
+    return re
+}
+
+// checkNumbers returns the error from converting the first number in a valid
+// record that can't be converted in the given mode. Only float64s can fail (by
+// being out of range), and only if they have an exponent or a lot of digits,
+// so nothing else is converted.
+func checkNumbers(record []byte, mode NumberMode) error {
+    if mode != NumberModeFloat64 {
+        return nil
+    }
+
+    inString := false
+    for i := 0; i < len(record); i++ {
+        c := record[i]
+
+        if inString == true {
+            if c == '\\' {
+                i++
+            } else if c == '"' {
+                inString = false
+            }
+
+            continue
+        } else if c == '"' {
+            inString = true
+            continue
+        } else if c != '-' && (c < '0' || c > '9') {
+            continue
+        }
+
+        // Outside of strings, only a number can have a '-' or a digit.
+        end := i + 1
+        for end < len(record) && strings.IndexByte("0123456789+-.eE", record[end]) != -1 {
+            end++
+        }
+
+        literal := record[i:end]
+        if len(literal) > 300 || bytes.IndexAny(literal, "eE") != -1 {
+            _, err := convertNumber(json.Number(literal), mode)
+            if err != nil {
+                return err
+            }
+        }
+
+        i = end - 1
+    }
+
+    return nil
+}
+
+// positionInRecord converts an offset within a record that starts at the
+// given position.
+func positionInRecord(record []byte, start Position, offset int64) Position {
+    pos := Position{
+        Offset: start.Offset + offset,
+        Line: start.Line,
+        Column: start.Column + int(offset),
+    }
+
+    before := record[:offset]
+    if i := bytes.LastIndexByte(before, '\n'); i != -1 {
+        pos.Line += bytes.Count(before, []byte{'\n'})
+        pos.Column = int(offset) - i
+    }
+
+    return pos
+}
@@ -0,0 +1,171 @@
+package jsonreader
+
+import (
+    "testing"
+    "strings"
+    "reflect"
+    "errors"
+    "io"
+    "strconv"
+
+    "github.com/dsoprea/go-logging"
+)
+
+func TestParser_RecordErrors(t *testing.T) {
+    data := "{\"aa\": 1}\n{\"bb\": 2]\n[1, 2\n\"cc\"\n3 4\n"
+
+    for _, native := range []bool{false, true} {
+        options := []ParserOption{WithDocumentMode(DocumentModeLines), WithRecordErrors()}
+        if native == false {
+            options = append(options, WithStandardDecoder())
+        }
+
+        p := NewParser(strings.NewReader(data), options...)
+
+        ts := make([]string, 0)
+        recordErrors := make([]RecordError, 0)
+
+        for {
+            token, err := p.Next()
+            if err == io.EOF {
+                break
+            }
+
+            log.PanicIf(err)
+
+            if re, ok := token.Value().(RecordError); ok == true {
+                recordErrors = append(recordErrors, re)
+                ts = append(ts, "/RECORDERROR")
+
+                continue
+            }
+
+            ts = append(ts, flattenToken(token))
+        }
+
+        expected := []string{
+            "/DOCUMENTSTART=1",
+            "/OBJECTOPEN",
+            ":aa",
+            "[aa] F 1.000000",
+            "/OBJECTCLOSE",
+            "@aa:1",
+            "/DOCUMENTEND=1",
+            "/RECORDERROR",
+            "/RECORDERROR",
+            "/DOCUMENTSTART=4",
+            "#STRING=cc",
+            "/DOCUMENTEND=4",
+            "/RECORDERROR",
+        }
+
+        if reflect.DeepEqual(ts, expected) == false {
+            t.Fatalf("Tokens not correct (native=%v):\nACTUAL: %v\nEXPECTED: %v", native, ts, expected)
+        } else if p.RecordErrorCount() != 3 {
+            t.Fatalf("Record error count not correct (native=%v): (%d)", native, p.RecordErrorCount())
+        }
+
+        re := recordErrors[0]
+
+        var se *SyntaxError
+        if re.Line != 2 || re.Offset != 10 || string(re.Raw) != `{"bb": 2]` {
+            t.Fatalf("First record error not correct (native=%v): %v", native, re)
+        } else if errors.As(re, &se) == false {
+            t.Fatalf("Expected a SyntaxError (native=%v).", native)
+        } else if se.Position.Line != 2 || se.Position.Column != 9 || errors.Is(re, ErrUnbalancedDelimiters) == false {
+            t.Fatalf("SyntaxError not correct (native=%v): %v", native, se)
+        }
+
+        if errors.Is(recordErrors[1], io.ErrUnexpectedEOF) == false {
+            t.Fatalf("Expected unexpected-EOF for second record error (native=%v): %v", native, recordErrors[1])
+        } else if recordErrors[2].Line != 5 || errors.Is(recordErrors[2], ErrSyntax) == false {
+            t.Fatalf("Third record error not correct (native=%v): %v", native, recordErrors[2])
+        }
+    }
+}
+
+func TestParser_RecordErrors_Conversion(t *testing.T) {
+    data := "{\"a\": 1e400}\n{\"b\": \"1e400\", \"c\": 1}\n"
+
+    for _, native := range []bool{false, true} {
+        options := []ParserOption{WithDocumentMode(DocumentModeLines), WithRecordErrors()}
+        if native == false {
+            options = append(options, WithStandardDecoder())
+        }
+
+        p := NewParser(strings.NewReader(data), options...)
+
+        token, err := p.Next()
+        log.PanicIf(err)
+
+        re, ok := token.Value().(RecordError)
+        if ok == false {
+            t.Fatalf("Expected a record error (native=%v): %v", native, token)
+        } else if re.Line != 1 || string(re.Raw) != `{"a": 1e400}` || errors.Is(re, strconv.ErrRange) == false {
+            t.Fatalf("Record error not correct (native=%v): %v", native, re)
+        }
+
+        // The number in the string doesn't have to be converted.
+        ts, err := flattenNext(p)
+        log.PanicIf(err)
+
+        expected := []string{
+            "/DOCUMENTSTART=2",
+            "/OBJECTOPEN",
+            ":b",
+            "[b] S 1e400",
+            ":c",
+            "[c] F 1.000000",
+            "/OBJECTCLOSE",
+            "@b:1e400 c:1",
+            "/DOCUMENTEND=2",
+        }
+
+        if reflect.DeepEqual(ts, expected) == false {
+            t.Fatalf("Tokens not correct (native=%v):\nACTUAL: %v\nEXPECTED: %v", native, ts, expected)
+        }
+    }
+}
+
+func TestParser_RecordErrors_Sequence(t *testing.T) {
+    data := "\x1e{\"aa\":\n tru}\n\x1e2\n"
+
+    p := NewParser(strings.NewReader(data), WithDocumentMode(DocumentModeSequence), WithRecordErrors())
+
+    token, err := p.Next()
+    log.PanicIf(err)
+
+    re, ok := token.Value().(RecordError)
+    if ok == false {
+        t.Fatalf("Expected a record error: %v", token)
+    }
+
+    var se *SyntaxError
+    if errors.As(re, &se) == false {
+        t.Fatalf("Expected a SyntaxError.")
+    } else if se.Position.Line != 2 || se.Position.Column != 5 || se.Position.Offset != 12 {
+        t.Fatalf("Position not correct: %v", se.Position)
+    }
+
+    ts, err := flattenNext(p)
+    log.PanicIf(err)
+
+    expected := []string{
+        "/DOCUMENTSTART=3",
+        "#FLOAT64=2.000000",
+        "/DOCUMENTEND=3",
+    }
+
+    if reflect.DeepEqual(ts, expected) == false {
+        t.Fatalf("Tokens not correct:\nACTUAL: %v\nEXPECTED: %v", ts, expected)
+    }
+}
+
+func TestParser_RecordErrors_Disabled(t *testing.T) {
+    p := NewParser(strings.NewReader("1\n[\n2\n"), WithDocumentMode(DocumentModeLines))
+
+    err := nextError(p)
+    if errors.Is(err, io.ErrUnexpectedEOF) == false {
+        t.Fatalf("Expected unexpected-EOF: %v", err)
+    }
+}
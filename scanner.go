@@ -0,0 +1,1166 @@
+package jsonreader
+
+import (
+    "bytes"
+    "fmt"
+    "io"
+    "strconv"
+    "unicode/utf16"
+    "unicode/utf8"
+
+    "encoding/json"
+)
+
+const (
+    // scannerBufferSize is the initial size of the read buffer. It grows if
+    // a single string or number doesn't fit.
+    scannerBufferSize = 64 * 1024
+
+    // maxInternedKeys and maxInternedKeyLength bound the cache of object
+    // keys. Records usually repeat the same keys, so this saves allocating
+    // each of them over and over.
+    maxInternedKeys = 1024
+    maxInternedKeyLength = 64
+)
+
+// tokenSource produces the raw tokens that the parser is built on. It has the
+// same semantics as the corresponding json.Decoder methods.
+type tokenSource interface {
+    Token() (json.Token, error)
+    More() bool
+    Decode(v interface{}) error
+    InputOffset() int64
+
+    // Skip consumes the next value without producing anything for it.
+    Skip() error
+
+    // TokenStart returns the offset of the first byte of the token or value
+    // most recently read.
+    TokenStart() int64
+
+    // NextOffset returns the offset of the next byte that isn't whitespace
+    // without consuming anything.
+    NextOffset() int64
+}
+
+// decoderSource is the tokenSource backed by json.Decoder. This is the
+// default.
+type decoderSource struct {
+    *json.Decoder
+
+    // reader is what the decoder reads from.
+    reader *startReader
+
+    // scratch is the buffer that nextValueOffset reads into.
+    scratch []byte
+
+    tokenStart int64
+}
+
+func newDecoderSource(r io.Reader) *decoderSource {
+    reader := &startReader{
+        r: r,
+    }
+
+    ds := &decoderSource{
+        Decoder: json.NewDecoder(reader),
+        reader: reader,
+        scratch: make([]byte, 64),
+    }
+
+    return ds
+}
+
+func (ds *decoderSource) Token() (json.Token, error) {
+    ds.markStart()
+    t, err := ds.Decoder.Token()
+    ds.finishStart()
+
+    return t, err
+}
+
+func (ds *decoderSource) Decode(v interface{}) error {
+    ds.markStart()
+    err := ds.Decoder.Decode(v)
+    ds.finishStart()
+
+    return err
+}
+
+// markStart records where the next token or value starts, as far as the
+// decoder has buffered it.
+func (ds *decoderSource) markStart() {
+    offset, exact := nextValueOffset(ds.Decoder, ds.scratch)
+
+    ds.tokenStart = offset
+    ds.reader.seeking = exact == false
+}
+
+// finishStart corrects the start of the token or value that was just read if
+// it wasn't buffered yet when markStart was called.
+func (ds *decoderSource) finishStart() {
+    if ds.reader.seeking == true {
+        // It was never found, so there was nothing more to read.
+        ds.reader.seeking = false
+    } else if ds.reader.found > ds.tokenStart {
+        ds.tokenStart = ds.reader.found
+    }
+}
+
+// Skip consumes the next value. The decoder still buffers the whole value,
+// but doesn't tokenize it.
+func (ds *decoderSource) Skip() error {
+    return ds.Decode(&discardValue{})
+}
+
+func (ds *decoderSource) TokenStart() int64 {
+    return ds.tokenStart
+}
+
+// NextOffset has the decoder read past any whitespace first so that the
+// offset is exact.
+func (ds *decoderSource) NextOffset() int64 {
+    ds.Decoder.More()
+
+    offset, _ := nextValueOffset(ds.Decoder, ds.scratch)
+    return offset
+}
+
+// scanError is a syntax error found by the native scanner.
+type scanError struct {
+    msg string
+    offset int64
+
+    // expected and actual are the same as SyntaxError.Expected and
+    // SyntaxError.Actual.
+    expected string
+    actual string
+}
+
+func (se *scanError) Error() string {
+    return se.msg
+}
+
+// scanState is what the scanner expects next.
+type scanState int
+
+const (
+    // A value at the top level (any number of them may follow each other).
+    scanTopValue scanState = iota
+
+    // The first value or the close of a list.
+    scanListStart
+
+    // A value after a comma in a list.
+    scanListValue
+
+    // A comma or the close of a list.
+    scanListComma
+
+    // The first key or the close of an object.
+    scanObjectStart
+
+    // A key after a comma in an object.
+    scanObjectKey
+
+    // The colon after a key.
+    scanObjectColon
+
+    // The value after a colon.
+    scanObjectValue
+
+    // A comma or the close of an object.
+    scanObjectComma
+)
+
+// scanKind is what nextToken read. The token itself is in the fields of the
+// scanner so that it doesn't have to be boxed.
+type scanKind int
+
+const (
+    // A '{', '[', '}', or ']' (in delim).
+    scanKindDelim scanKind = iota
+
+    // An object key (in str).
+    scanKindKey
+
+    // A string (in str).
+    scanKindString
+
+    // A number as a float64 (in float).
+    scanKindFloat
+
+    // A number as a json.Number (in str).
+    scanKindNumber
+
+    // A bool (in boolean).
+    scanKindBool
+
+    scanKindNull
+)
+
+// scanner is a tokenSource that reads the bytes itself rather than going
+// through json.Decoder. It allocates far less: nothing is buffered beyond the
+// token being read, delimiters are never re-validated by a second scanner,
+// and skipped values are only checked for balance.
+type scanner struct {
+    r io.Reader
+
+    buf []byte
+
+    // pos is the next byte to be read from buf and end is the end of the
+    // valid data.
+    pos int
+    end int
+
+    // offset is the input offset of buf[0].
+    offset int64
+
+    // readErr is the error from the last read. It's only reported once
+    // the buffered data has been used.
+    readErr error
+
+    // err is the first syntax error. The scanner can't continue after it.
+    err error
+
+    useNumber bool
+
+    state scanState
+
+    // stack has a '{' or '[' for every open object or list.
+    stack []byte
+
+    keys map[string]string
+
+    // tokenStart is the offset of the token or value most recently read.
+    tokenStart int64
+
+    // These are the token most recently read by nextToken. Which one is set
+    // depends on its kind.
+    delim byte
+    str string
+    float float64
+    boolean bool
+}
+
+func newScanner(r io.Reader) *scanner {
+    return &scanner{
+        r: r,
+        buf: make([]byte, scannerBufferSize),
+        stack: make([]byte, 0),
+        keys: make(map[string]string),
+    }
+}
+
+// reset starts reading from another reader, keeping the buffer and the
+// interned keys.
+func (s *scanner) reset(r io.Reader) {
+    *s = scanner{
+        r: r,
+        buf: s.buf,
+        stack: s.stack[:0],
+        keys: s.keys,
+        useNumber: s.useNumber,
+    }
+}
+
+// fill reads more data into the buffer. Anything before keep is discarded to
+// make room, and the buffer grows if that isn't enough. The new position of
+// keep is returned.
+func (s *scanner) fill(keep int) (newKeep int, err error) {
+    if s.readErr != nil {
+        return keep, s.readErr
+    }
+
+    if keep > 0 {
+        copy(s.buf, s.buf[keep:s.end])
+
+        s.end -= keep
+        s.pos -= keep
+        s.offset += int64(keep)
+        keep = 0
+    }
+
+    if s.end == len(s.buf) {
+        buf := make([]byte, len(s.buf) * 2)
+        copy(buf, s.buf[:s.end])
+        s.buf = buf
+    }
+
+    for {
+        n, err := s.r.Read(s.buf[s.end:])
+        s.end += n
+
+        if err != nil {
+            s.readErr = err
+        }
+
+        if n > 0 || err != nil {
+            break
+        }
+    }
+
+    if s.readErr != nil && s.end == s.pos {
+        return keep, s.readErr
+    }
+
+    return keep, nil
+}
+
+// peek skips whitespace and returns the next byte without consuming it. If
+// the input ends first, the whitespace isn't counted by InputOffset() (like
+// json.Decoder), so an unexpected EOF is reported right after the last thing
+// in the input.
+func (s *scanner) peek() (c byte, err error) {
+    start := s.InputOffset()
+
+    for {
+        for ; s.pos < s.end; s.pos++ {
+            c := s.buf[s.pos]
+            if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+                return c, nil
+            }
+        }
+
+        if _, err := s.fill(s.pos); err != nil {
+            // Everything that was buffered has been used.
+            s.offset = start
+            s.pos = 0
+            s.end = 0
+
+            return 0, err
+        }
+    }
+}
+
+func (s *scanner) syntaxError(expected, actual string, format string, args ...interface{}) error {
+    s.err = &scanError{
+        msg: fmt.Sprintf(format, args...),
+        offset: s.InputOffset(),
+        expected: expected,
+        actual: actual,
+    }
+
+    return s.err
+}
+
+// invalidCharacter reports a character that isn't allowed in the current
+// state.
+func (s *scanner) invalidCharacter(c byte) error {
+    return s.syntaxError(s.expected(), fmt.Sprintf("%q", c), "invalid character %q", c)
+}
+
+// expected describes what is allowed in the current state.
+func (s *scanner) expected() string {
+    switch s.state {
+    case scanListStart:
+        return "value or ']'"
+    case scanListComma:
+        return "',' or ']'"
+    case scanObjectStart:
+        return "object key or '}'"
+    case scanObjectKey:
+        return "object key"
+    case scanObjectColon:
+        return "':'"
+    case scanObjectComma:
+        return "',' or '}'"
+    }
+
+    return "value"
+}
+
+// unexpectedEOF converts a clean EOF in the middle of a value to
+// io.ErrUnexpectedEOF.
+func (s *scanner) unexpectedEOF(err error) error {
+    if err == io.EOF {
+        err = io.ErrUnexpectedEOF
+    }
+
+    s.err = err
+    return err
+}
+
+// TokenStart returns the offset of the token or value most recently read.
+func (s *scanner) TokenStart() int64 {
+    return s.tokenStart
+}
+
+// NextOffset returns the offset of the next byte that isn't whitespace.
+func (s *scanner) NextOffset() int64 {
+    if s.err == nil {
+        s.peek()
+    }
+
+    return s.InputOffset()
+}
+
+// InputOffset returns the input offset of the next byte to be read.
+func (s *scanner) InputOffset() int64 {
+    return s.offset + int64(s.pos)
+}
+
+func (s *scanner) valueAllowed() bool {
+    switch s.state {
+    case scanTopValue, scanListStart, scanListValue, scanObjectValue:
+        return true
+    }
+
+    return false
+}
+
+// valueEnd updates the state after a complete value.
+func (s *scanner) valueEnd() {
+    len_ := len(s.stack)
+    if len_ == 0 {
+        s.state = scanTopValue
+    } else if s.stack[len_ - 1] == '[' {
+        s.state = scanListComma
+    } else {
+        s.state = scanObjectComma
+    }
+}
+
+// prepareForValue consumes a pending comma or colon before a value is
+// decoded or skipped.
+func (s *scanner) prepareForValue() (err error) {
+    if s.err != nil {
+        return s.err
+    }
+
+    if s.state == scanListComma || s.state == scanObjectColon {
+        c, err := s.peek()
+        if err != nil {
+            return s.unexpectedEOF(err)
+        }
+
+        if s.state == scanListComma {
+            if c != ',' {
+                return s.invalidCharacter(c)
+            }
+
+            s.state = scanListValue
+        } else {
+            if c != ':' {
+                return s.invalidCharacter(c)
+            }
+
+            s.state = scanObjectValue
+        }
+
+        s.pos++
+    }
+
+    if s.valueAllowed() == false {
+        return s.syntaxError(s.expected(), "value", "not at beginning of value")
+    }
+
+    return nil
+}
+
+// More returns true if there is another element in the current list or
+// object.
+func (s *scanner) More() bool {
+    if s.err != nil {
+        return false
+    }
+
+    c, err := s.peek()
+    return err == nil && c != ']' && c != '}'
+}
+
+// Token returns the next token, like json.Decoder.Token.
+func (s *scanner) Token() (token json.Token, err error) {
+    kind, err := s.nextToken()
+    if err != nil {
+        return nil, err
+    }
+
+    return s.tokenValue(kind), nil
+}
+
+// tokenValue returns the token most recently read by nextToken the way that
+// json.Decoder.Token would.
+func (s *scanner) tokenValue(kind scanKind) json.Token {
+    switch kind {
+    case scanKindDelim:
+        return json.Delim(s.delim)
+    case scanKindKey, scanKindString:
+        return s.str
+    case scanKindFloat:
+        return s.float
+    case scanKindNumber:
+        return json.Number(s.str)
+    case scanKindBool:
+        return s.boolean
+    }
+
+    return nil
+}
+
+// nextToken reads the next token into the fields of the scanner and returns
+// its kind.
+func (s *scanner) nextToken() (kind scanKind, err error) {
+    if s.err != nil {
+        return 0, s.err
+    }
+
+    for {
+        c, err := s.peek()
+        if err != nil {
+            if err == io.EOF && s.state != scanTopValue {
+                // We leave it to the parser to decide that this is
+                // unexpected, like json.Decoder does.
+                return 0, io.EOF
+            } else if err != io.EOF {
+                s.err = err
+            }
+
+            return 0, err
+        }
+
+        s.tokenStart = s.InputOffset()
+
+        switch c {
+        case '[', '{':
+            if s.valueAllowed() == false {
+                return 0, s.invalidCharacter(c)
+            }
+
+            s.pos++
+            s.stack = append(s.stack, c)
+
+            if c == '[' {
+                s.state = scanListStart
+            } else {
+                s.state = scanObjectStart
+            }
+
+            s.delim = c
+            return scanKindDelim, nil
+        case ']':
+            if s.state != scanListStart && s.state != scanListComma {
+                return 0, s.invalidCharacter(c)
+            }
+
+            s.pos++
+            s.stack = s.stack[:len(s.stack) - 1]
+            s.valueEnd()
+
+            s.delim = c
+            return scanKindDelim, nil
+        case '}':
+            if s.state != scanObjectStart && s.state != scanObjectComma {
+                return 0, s.invalidCharacter(c)
+            }
+
+            s.pos++
+            s.stack = s.stack[:len(s.stack) - 1]
+            s.valueEnd()
+
+            s.delim = c
+            return scanKindDelim, nil
+        case ':':
+            if s.state != scanObjectColon {
+                return 0, s.invalidCharacter(c)
+            }
+
+            s.pos++
+            s.state = scanObjectValue
+
+            continue
+        case ',':
+            if s.state == scanListComma {
+                s.pos++
+                s.state = scanListValue
+
+                continue
+            } else if s.state == scanObjectComma {
+                s.pos++
+                s.state = scanObjectKey
+
+                continue
+            }
+
+            return 0, s.invalidCharacter(c)
+        case '"':
+            if s.state == scanObjectStart || s.state == scanObjectKey {
+                key, err := s.readKey()
+                if err != nil {
+                    return 0, err
+                }
+
+                s.str = key
+                s.state = scanObjectColon
+                return scanKindKey, nil
+            }
+        }
+
+        if s.valueAllowed() == false {
+            return 0, s.invalidCharacter(c)
+        }
+
+        kind, err := s.readScalar(c)
+        if err != nil {
+            return 0, err
+        }
+
+        s.valueEnd()
+        return kind, nil
+    }
+}
+
+// readScalar reads a string, number, or literal starting with the given byte
+// into the fields of the scanner.
+func (s *scanner) readScalar(c byte) (kind scanKind, err error) {
+    switch c {
+    case '"':
+        s.str, err = s.readString()
+        return scanKindString, err
+    case 't':
+        s.boolean = true
+        return scanKindBool, s.readLiteral("true")
+    case 'f':
+        s.boolean = false
+        return scanKindBool, s.readLiteral("false")
+    case 'n':
+        return scanKindNull, s.readLiteral("null")
+    }
+
+    if c == '-' || (c >= '0' && c <= '9') {
+        return s.readNumber()
+    }
+
+    return 0, s.syntaxError("value", fmt.Sprintf("%q", c), "invalid character %q looking for beginning of value", c)
+}
+
+func (s *scanner) readLiteral(literal string) (err error) {
+    for s.end - s.pos < len(literal) {
+        if _, err := s.fill(s.pos); err != nil {
+            return s.unexpectedEOF(err)
+        }
+    }
+
+    if string(s.buf[s.pos:s.pos + len(literal)]) != literal {
+        return s.syntaxError(literal, string(s.buf[s.pos:s.pos + len(literal)]), "invalid literal")
+    }
+
+    s.pos += len(literal)
+    return nil
+}
+
+func isNumberByte(c byte) bool {
+    return (c >= '0' && c <= '9') || c == '-' || c == '+' || c == '.' || c == 'e' || c == 'E'
+}
+
+// isValidNumber checks the JSON number grammar.
+func isValidNumber(b []byte) bool {
+    i := 0
+    if i < len(b) && b[i] == '-' {
+        i++
+    }
+
+    if i >= len(b) {
+        return false
+    }
+
+    if b[i] == '0' {
+        i++
+    } else if b[i] >= '1' && b[i] <= '9' {
+        for i < len(b) && b[i] >= '0' && b[i] <= '9' {
+            i++
+        }
+    } else {
+        return false
+    }
+
+    if i < len(b) && b[i] == '.' {
+        i++
+
+        start := i
+        for i < len(b) && b[i] >= '0' && b[i] <= '9' {
+            i++
+        }
+
+        if i == start {
+            return false
+        }
+    }
+
+    if i < len(b) && (b[i] == 'e' || b[i] == 'E') {
+        i++
+
+        if i < len(b) && (b[i] == '+' || b[i] == '-') {
+            i++
+        }
+
+        start := i
+        for i < len(b) && b[i] >= '0' && b[i] <= '9' {
+            i++
+        }
+
+        if i == start {
+            return false
+        }
+    }
+
+    return i == len(b)
+}
+
+// readNumber reads a number into the fields of the scanner.
+func (s *scanner) readNumber() (kind scanKind, err error) {
+    i := s.pos
+    for {
+        for ; i < s.end && isNumberByte(s.buf[i]) == true; i++ {
+        }
+
+        if i < s.end {
+            break
+        }
+
+        // The number might continue in the next read.
+        n := i - s.pos
+        if _, err := s.fill(s.pos); err == io.EOF {
+            i = s.pos + n
+            break
+        } else if err != nil {
+            s.err = err
+            return 0, err
+        }
+
+        i = s.pos + n
+    }
+
+    raw := s.buf[s.pos:i]
+    if isValidNumber(raw) == false {
+        return 0, s.syntaxError("number", string(raw), "invalid number")
+    }
+
+    s.pos = i
+
+    if s.useNumber == true {
+        s.str = string(raw)
+        return scanKindNumber, nil
+    }
+
+    // Small integers are exact as float64 and don't need the general parser.
+    if len(raw) <= 15 {
+        if f, ok := parseSmallInteger(raw); ok == true {
+            s.float = f
+            return scanKindFloat, nil
+        }
+    }
+
+    f, err := strconv.ParseFloat(string(raw), 64)
+    if err != nil {
+        return 0, s.syntaxError("number", string(raw), "number out of range")
+    }
+
+    s.float = f
+    return scanKindFloat, nil
+}
+
+// readKey reads an object key, reusing a previous copy of it if there is one.
+func (s *scanner) readKey() (key string, err error) {
+    raw, simple, err := s.readStringBytes()
+    if err != nil {
+        return "", err
+    }
+
+    if simple == false || len(raw) > maxInternedKeyLength {
+        return s.decodeString(raw, simple)
+    }
+
+    // This lookup doesn't allocate.
+    if interned, found := s.keys[string(raw)]; found == true {
+        return interned, nil
+    }
+
+    key = string(raw)
+    if len(s.keys) < maxInternedKeys {
+        s.keys[key] = key
+    }
+
+    return key, nil
+}
+
+// parseSmallInteger converts a number having only digits (and optionally a
+// sign).
+func parseSmallInteger(raw []byte) (f float64, ok bool) {
+    negative := false
+    if raw[0] == '-' {
+        negative = true
+        raw = raw[1:]
+    }
+
+    n := int64(0)
+    for _, c := range raw {
+        if c < '0' || c > '9' {
+            return 0, false
+        }
+
+        n = n * 10 + int64(c - '0')
+    }
+
+    if negative == true {
+        // Preserve negative zero, like ParseFloat does.
+        if n == 0 {
+            return 0, false
+        }
+
+        n = -n
+    }
+
+    return float64(n), true
+}
+
+// readString reads a quoted string. The current byte is the opening quote.
+func (s *scanner) readString() (value string, err error) {
+    raw, simple, err := s.readStringBytes()
+    if err != nil {
+        return "", err
+    }
+
+    return s.decodeString(raw, simple)
+}
+
+// readStringBytes finds the end of a quoted string and returns its content as
+// it appears in the buffer. It's only valid until the next read. If simple is
+// true, there's nothing in it that needs to be decoded.
+func (s *scanner) readStringBytes() (raw []byte, simple bool, err error) {
+    i := s.pos + 1
+    simple = true
+
+    for {
+        for ; i < s.end; i++ {
+            c := s.buf[i]
+            if c == '"' {
+                break
+            } else if c == '\\' {
+                simple = false
+
+                // Don't mistake an escaped quote for the end.
+                i++
+            } else if c < 0x20 {
+                s.pos = i
+                return nil, false, s.syntaxError("string", fmt.Sprintf("%q", c), "invalid character %q in string literal", c)
+            } else if c >= utf8.RuneSelf {
+                simple = false
+            }
+        }
+
+        if i < s.end {
+            break
+        }
+
+        n := i - s.pos
+        if _, err := s.fill(s.pos); err != nil {
+            return nil, false, s.unexpectedEOF(err)
+        }
+
+        i = s.pos + n
+    }
+
+    raw = s.buf[s.pos + 1:i]
+    s.pos = i + 1
+
+    return raw, simple, nil
+}
+
+func (s *scanner) decodeString(raw []byte, simple bool) (value string, err error) {
+    if simple == true {
+        return string(raw), nil
+    }
+
+    value, ok := unquoteBytes(raw)
+    if ok == false {
+        return "", s.syntaxError("string", "", "invalid escape in string literal")
+    }
+
+    return value, nil
+}
+
+// unquoteBytes decodes the escapes in the content of a string literal.
+// Invalid UTF-8 is replaced with U+FFFD, like encoding/json does.
+func unquoteBytes(raw []byte) (value string, ok bool) {
+    b := make([]byte, 0, len(raw))
+
+    for i := 0; i < len(raw); {
+        c := raw[i]
+
+        if c == '\\' {
+            if i + 1 >= len(raw) {
+                return "", false
+            }
+
+            switch raw[i + 1] {
+            case '"', '\\', '/':
+                b = append(b, raw[i + 1])
+            case 'b':
+                b = append(b, '\b')
+            case 'f':
+                b = append(b, '\f')
+            case 'n':
+                b = append(b, '\n')
+            case 'r':
+                b = append(b, '\r')
+            case 't':
+                b = append(b, '\t')
+            case 'u':
+                r, ok := parseHex4(raw[i + 2:])
+                if ok == false {
+                    return "", false
+                }
+
+                i += 6
+
+                if utf16.IsSurrogate(r) == true {
+                    // Look for the second half of the pair.
+                    if i + 1 < len(raw) && raw[i] == '\\' && raw[i + 1] == 'u' {
+                        r2, ok := parseHex4(raw[i + 2:])
+                        if ok == true {
+                            if dec := utf16.DecodeRune(r, r2); dec != utf8.RuneError {
+                                b = utf8.AppendRune(b, dec)
+                                i += 6
+
+                                continue
+                            }
+                        }
+                    }
+
+                    r = utf8.RuneError
+                }
+
+                b = utf8.AppendRune(b, r)
+                continue
+            default:
+                return "", false
+            }
+
+            i += 2
+            continue
+        }
+
+        if c < utf8.RuneSelf {
+            b = append(b, c)
+            i++
+
+            continue
+        }
+
+        r, size := utf8.DecodeRune(raw[i:])
+        if r == utf8.RuneError && size == 1 {
+            b = utf8.AppendRune(b, utf8.RuneError)
+        } else {
+            b = append(b, raw[i:i + size]...)
+        }
+
+        i += size
+    }
+
+    return string(b), true
+}
+
+func parseHex4(b []byte) (r rune, ok bool) {
+    if len(b) < 4 {
+        return 0, false
+    }
+
+    for _, c := range b[:4] {
+        switch {
+        case c >= '0' && c <= '9':
+            c = c - '0'
+        case c >= 'a' && c <= 'f':
+            c = c - 'a' + 10
+        case c >= 'A' && c <= 'F':
+            c = c - 'A' + 10
+        default:
+            return 0, false
+        }
+
+        r = r * 16 + rune(c)
+    }
+
+    return r, true
+}
+
+// skipValue consumes the next value by only checking that brackets and quotes
+// are balanced. If capture is not nil, the bytes of the value are appended to
+// it. Unless capturing, nothing is buffered beyond what's been read.
+func (s *scanner) skipValue(capture *[]byte) (err error) {
+    c, err := s.peek()
+    if err != nil {
+        return s.unexpectedEOF(err)
+    }
+
+    start := s.pos
+    s.tokenStart = s.InputOffset()
+
+    // flush copies whatever we've scanned so far before the buffer is
+    // reused.
+    flush := func(to int) {
+        if capture != nil {
+            *capture = append(*capture, s.buf[start:to]...)
+        }
+
+        start = to
+    }
+
+    if c != '{' && c != '[' && c != '"' {
+        // Numbers and literals are short enough to just read.
+        if _, err := s.readScalar(c); err != nil {
+            return err
+        }
+
+        // Reading might have moved the scalar within the buffer, but it's
+        // still contiguous.
+        start = s.pos - int(s.InputOffset() - s.tokenStart)
+
+        flush(s.pos)
+        return nil
+    }
+
+    // We have our own small stack so that mismatched brackets are caught.
+    nesting := make([]byte, 0, 8)
+    inString := false
+
+    // lastEnd is where the last byte that isn't whitespace ends, which is
+    // where the value is cut off if the input ends.
+    lastEnd := s.InputOffset()
+
+    i := s.pos
+    for {
+        for ; i < s.end; i++ {
+            c := s.buf[i]
+
+            if inString == true {
+                if c == '\\' {
+                    // The escaped byte might not have been read yet.
+                    if i + 1 >= s.end {
+                        break
+                    }
+
+                    i++
+                } else if c == '"' {
+                    inString = false
+
+                    if len(nesting) == 0 {
+                        s.pos = i + 1
+                        flush(s.pos)
+
+                        return nil
+                    }
+                }
+
+                continue
+            }
+
+            switch c {
+            case '"':
+                inString = true
+            case '{', '[':
+                nesting = append(nesting, c)
+            case '}', ']':
+                len_ := len(nesting)
+                if len_ == 0 || (c == '}') != (nesting[len_ - 1] == '{') {
+                    s.pos = i
+                    expected := "value"
+                    if len_ > 0 {
+                        expected = fmt.Sprintf("'%c'", closerFor(nesting[len_ - 1]))
+                    }
+
+                    return s.syntaxError(expected, fmt.Sprintf("%q", c), "invalid character %q in skipped value", c)
+                }
+
+                nesting = nesting[:len_ - 1]
+                if len_ == 1 {
+                    s.pos = i + 1
+                    flush(s.pos)
+
+                    return nil
+                }
+            }
+        }
+
+        flush(i)
+
+        trimmed := bytes.TrimRight(s.buf[s.pos:i], " \t\r\n")
+        if len(trimmed) > 0 {
+            lastEnd = s.offset + int64(s.pos + len(trimmed))
+        }
+
+        s.pos = i
+        if _, err := s.fill(s.pos); err != nil {
+            // Everything that was buffered has been used.
+            s.offset = lastEnd
+            s.pos = 0
+            s.end = 0
+
+            return s.unexpectedEOF(err)
+        }
+
+        i = s.pos
+        start = s.pos
+    }
+}
+
+// closerFor returns the delimiter that closes the given one.
+func closerFor(opener byte) byte {
+    if opener == '{' {
+        return '}'
+    }
+
+    return ']'
+}
+
+// Skip consumes the next value without tokenizing it.
+func (s *scanner) Skip() (err error) {
+    if err := s.prepareForValue(); err != nil {
+        return err
+    }
+
+    if err := s.skipValue(nil); err != nil {
+        return err
+    }
+
+    s.valueEnd()
+    return nil
+}
+
+// Decode reads the next value and unmarshals it into v, like
+// json.Decoder.Decode.
+func (s *scanner) Decode(v interface{}) (err error) {
+    if err := s.prepareForValue(); err != nil {
+        return err
+    }
+
+    if _, ok := v.(*discardValue); ok == true {
+        if err := s.skipValue(nil); err != nil {
+            return err
+        }
+
+        s.valueEnd()
+        return nil
+    }
+
+    raw := make([]byte, 0)
+    if err := s.skipValue(&raw); err != nil {
+        return err
+    }
+
+    s.valueEnd()
+
+    if rm, ok := v.(*json.RawMessage); ok == true {
+        *rm = raw
+        return nil
+    }
+
+    if s.useNumber == true {
+        d := json.NewDecoder(bytes.NewReader(raw))
+        d.UseNumber()
+
+        return d.Decode(v)
+    }
+
+    return json.Unmarshal(raw, v)
+}
@@ -0,0 +1,478 @@
+package jsonreader
+
+import (
+    "testing"
+    "os"
+    "path"
+    "fmt"
+    "io"
+    "errors"
+    "strings"
+    "bytes"
+    "reflect"
+
+    "encoding/json"
+
+    "github.com/dsoprea/go-logging"
+)
+
+var (
+    scannerTestDocuments = []string{
+        `{"aa": "bb", "cc": [1, 2.5, -3e2, 0, -0.125E+2], "dd": {"ee": null, "ff": true, "gg": false}}`,
+        `[]`,
+        `{}`,
+        `[[], {}, [[{}]], ""]`,
+        `"just a string"`,
+        `123.456`,
+        ` 1 2 "three" [4] {"five": 5} `,
+        `{"escapes": "a\"b\\c\/d\b\f\n\r\tAé中😀"}`,
+        `{"lone": "\ud83d x \ude00"}`,
+        "{\"utf8\": \"héllo wörld \U0001F600\"}",
+        "{\"invalid\": \"a\xffb\"}",
+        `{"big": 12345678901234567890123, "small": 1e-400}`,
+        "[\n\t1 ,\r\n 2\n]",
+    }
+)
+
+// flattenSource returns the tokens of a tokenSource as strings.
+func flattenSource(source tokenSource) (ts []string, err error) {
+    ts = make([]string, 0)
+    for {
+        token, err := source.Token()
+        if err == io.EOF {
+            break
+        } else if err != nil {
+            return nil, err
+        }
+
+        ts = append(ts, fmt.Sprintf("%T:%v", token, token))
+    }
+
+    return ts, nil
+}
+
+func TestScanner_Token_MatchesDecoder(t *testing.T) {
+    for _, useNumber := range []bool{false, true} {
+        for i, document := range scannerTestDocuments {
+            ds := newDecoderSource(strings.NewReader(document))
+            if useNumber == true {
+                ds.UseNumber()
+            }
+
+            expected, err := flattenSource(ds)
+            log.PanicIf(err)
+
+            // Read one byte at a time so that every token straddles a
+            // read.
+            s := newScanner(&byteReader{data: []byte(document)})
+            s.useNumber = useNumber
+
+            actual, err := flattenSource(s)
+            if err != nil {
+                t.Fatalf("Document (%d) failed: %v", i, err)
+            }
+
+            if reflect.DeepEqual(actual, expected) == false {
+                t.Fatalf("Document (%d) tokens not correct:\nACTUAL: %v\nEXPECTED: %v", i, actual, expected)
+            }
+        }
+    }
+}
+
+func TestScanner_Token_SyntaxErrors(t *testing.T) {
+    documents := []string{
+        `{"aa" 1}`,
+        `{"aa": 1 "bb": 2}`,
+        `[1 2]`,
+        `[1,]`,
+        `{"aa": 1,}`,
+        `{1: 2}`,
+        `[}`,
+        `{]`,
+        `]`,
+        `[tru]`,
+        `[01]`,
+        `[1.]`,
+        `[-]`,
+        `[1e]`,
+        "[\"a\nb\"]",
+        `["\x"]`,
+        `["\'"]`,
+        `["\u12"]`,
+        `[1x]`,
+    }
+
+    for _, document := range documents {
+        _, err := flattenSource(newScanner(strings.NewReader(document)))
+        if err == nil {
+            t.Fatalf("Expected error for [%s].", document)
+        }
+    }
+}
+
+func TestScanner_Token_Truncated(t *testing.T) {
+    documents := []string{
+        `["abc`,
+        `[tr`,
+    }
+
+    for _, document := range documents {
+        _, err := flattenSource(newScanner(strings.NewReader(document)))
+        if err != io.ErrUnexpectedEOF {
+            t.Fatalf("Expected unexpected-EOF for [%s]: %v", document, err)
+        }
+    }
+}
+
+func TestScanner_Skip(t *testing.T) {
+    document := `{"aa": {"bb": ["}", "\"]", [1, {"cc": null}]]}, "dd": 2}`
+
+    s := newScanner(&byteReader{data: []byte(document)})
+
+    _, err := s.Token()
+    log.PanicIf(err)
+
+    key, err := s.Token()
+    log.PanicIf(err)
+
+    if key != "aa" {
+        t.Fatalf("Key not correct: [%v]", key)
+    }
+
+    err = s.Skip()
+    log.PanicIf(err)
+
+    ts, err := flattenSource(s)
+    log.PanicIf(err)
+
+    expected := []string{
+        "string:dd",
+        "float64:2",
+        "json.Delim:}",
+    }
+
+    if reflect.DeepEqual(ts, expected) == false {
+        t.Fatalf("Tokens after skip not correct: %v", ts)
+    }
+}
+
+func TestScanner_Skip_Mismatched(t *testing.T) {
+    s := newScanner(strings.NewReader(`[{"aa": [1}]]`))
+
+    _, err := s.Token()
+    log.PanicIf(err)
+
+    err = s.Skip()
+    if err == nil {
+        t.Fatalf("Expected error for mismatched brackets.")
+    }
+}
+
+func TestScanner_Decode(t *testing.T) {
+    document := `[{"aa": 1, "bb": [true, "x"]}, 12345678901234567890]`
+
+    s := newScanner(&byteReader{data: []byte(document)})
+    s.useNumber = true
+
+    _, err := s.Token()
+    log.PanicIf(err)
+
+    var value map[string]interface{}
+    err = s.Decode(&value)
+    log.PanicIf(err)
+
+    expected := map[string]interface{}{
+        "aa": json.Number("1"),
+        "bb": []interface{}{true, "x"},
+    }
+
+    if reflect.DeepEqual(value, expected) == false {
+        t.Fatalf("Decoded value not correct: %v", value)
+    }
+
+    var raw json.RawMessage
+    err = s.Decode(&raw)
+    log.PanicIf(err)
+
+    if string(raw) != "12345678901234567890" {
+        t.Fatalf("Raw value not correct: [%s]", raw)
+    }
+
+    token, err := s.Token()
+    log.PanicIf(err)
+
+    if token != json.Delim(']') {
+        t.Fatalf("Expected list close: [%v]", token)
+    }
+}
+
+func TestScanner_Decode_LargeInput(t *testing.T) {
+    // Make sure that scalars that cross the end of the buffer are captured
+    // correctly.
+    b := new(bytes.Buffer)
+    b.WriteString("[")
+
+    for i := 0; i < 50000; i++ {
+        if i > 0 {
+            b.WriteString(",")
+        }
+
+        fmt.Fprintf(b, "%d", i)
+    }
+
+    b.WriteString("]")
+
+    if b.Len() <= 64 * 1024 {
+        t.Fatalf("Document isn't larger than the buffer: (%d)", b.Len())
+    }
+
+    values := make([]int, 0)
+    for value, err := range Elements[int](bytes.NewReader(b.Bytes()), "$", WithNativeScanner()) {
+        log.PanicIf(err)
+
+        values = append(values, value)
+    }
+
+    if len(values) != 50000 {
+        t.Fatalf("Value count not correct: (%d)", len(values))
+    }
+
+    for i, value := range values {
+        if value != i {
+            t.Fatalf("Value (%d) not correct: (%d)", i, value)
+        }
+    }
+}
+
+func TestScanner_Decode_Truncated(t *testing.T) {
+    s := newScanner(&byteReader{data: []byte(`[1,2`)})
+
+    _, err := s.Token()
+    log.PanicIf(err)
+
+    var value int
+    err = s.Decode(&value)
+    log.PanicIf(err)
+
+    err = s.Decode(&value)
+    log.PanicIf(err)
+
+    if value != 2 {
+        t.Fatalf("Value not correct: (%d)", value)
+    }
+
+    // The parser reports the missing end of the list.
+    _, err = s.Token()
+    if err != io.EOF {
+        t.Fatalf("Expected EOF: %v", err)
+    }
+}
+
+func TestParser_NativeScanner(t *testing.T) {
+    filepath := path.Join(testingAssetsPath, "data1.json")
+
+    data, err := os.ReadFile(filepath)
+    log.PanicIf(err)
+
+    for _, mode := range []NumberMode{NumberModeFloat64, NumberModeExact} {
+        expected, err := flattenNext(NewParser(bytes.NewReader(data), WithNumberMode(mode), WithStandardDecoder()))
+        log.PanicIf(err)
+
+        p := NewParser(bytes.NewReader(data), WithNumberMode(mode), WithNativeScanner())
+
+        actual, err := flattenNext(p)
+        log.PanicIf(err)
+
+        if reflect.DeepEqual(actual, expected) == false {
+            t.Fatalf("Tokens not correct for mode [%s]:\nACTUAL: %v\nEXPECTED: %v", mode, actual, expected)
+        }
+    }
+}
+
+func TestParser_NativeScanner_Truncated(t *testing.T) {
+    p := NewParser(strings.NewReader(`{"aa": [1, 2`), WithNativeScanner())
+
+    err := nextError(p)
+    if errors.Is(err, io.ErrUnexpectedEOF) == false {
+        t.Fatalf("Expected unexpected-EOF: %v", err)
+    }
+}
+
+// benchmarkDocument returns a large document of many small records.
+func benchmarkDocument() []byte {
+    b := new(bytes.Buffer)
+    b.WriteString(`{"locations": [`)
+
+    for i := 0; i < 100000; i++ {
+        if i > 0 {
+            b.WriteString(",")
+        }
+
+        fmt.Fprintf(b, `{"timestampMs": "%d", "latitudeE7": %d, "longitudeE7": %d, "accuracy": 20, "activity": [{"type": "STILL", "confidence": 100}]}`, 1500000000000 + i, 400000000 + i, -740000000 - i)
+    }
+
+    b.WriteString(`]}`)
+    return b.Bytes()
+}
+
+// targetThroughput is the rate, in MB/s, that reading the input without
+// building the tokens of every value has to reach.
+const targetThroughput = 200.0
+
+// checkThroughput fails the benchmark if it didn't reach targetThroughput.
+func checkThroughput(b *testing.B, size int) {
+    b.StopTimer()
+
+    seconds := b.Elapsed().Seconds()
+    if seconds == 0 {
+        return
+    }
+
+    throughput := float64(size) * float64(b.N) / 1e6 / seconds
+    if throughput < targetThroughput {
+        b.Errorf("Throughput below target: (%.2f) MB/s < (%.2f) MB/s", throughput, targetThroughput)
+    }
+}
+
+func benchmarkSource(b *testing.B, newSource func(r io.Reader) tokenSource) {
+    data := benchmarkDocument()
+
+    b.SetBytes(int64(len(data)))
+    b.ReportAllocs()
+    b.ResetTimer()
+
+    for i := 0; i < b.N; i++ {
+        source := newSource(bytes.NewReader(data))
+
+        for {
+            _, err := source.Token()
+            if err == io.EOF {
+                break
+            }
+
+            log.PanicIf(err)
+        }
+    }
+}
+
+func BenchmarkTokens_Decoder(b *testing.B) {
+    benchmarkSource(b, func(r io.Reader) tokenSource {
+        return newDecoderSource(r)
+    })
+}
+
+func BenchmarkTokens_NativeScanner(b *testing.B) {
+    benchmarkSource(b, func(r io.Reader) tokenSource {
+        return newScanner(r)
+    })
+}
+
+// BenchmarkTokens_NativeScanner_Unboxed reads the tokens the way that the
+// parser does, without boxing them.
+func BenchmarkTokens_NativeScanner_Unboxed(b *testing.B) {
+    data := benchmarkDocument()
+
+    b.SetBytes(int64(len(data)))
+    b.ReportAllocs()
+    b.ResetTimer()
+
+    for i := 0; i < b.N; i++ {
+        s := newScanner(bytes.NewReader(data))
+
+        for {
+            _, err := s.nextToken()
+            if err == io.EOF {
+                break
+            }
+
+            log.PanicIf(err)
+        }
+    }
+
+    checkThroughput(b, len(data))
+}
+
+// benchmarkNext reads all of the tokens and returns the size of the document.
+func benchmarkNext(b *testing.B, options ...ParserOption) int {
+    data := benchmarkDocument()
+
+    b.SetBytes(int64(len(data)))
+    b.ReportAllocs()
+    b.ResetTimer()
+
+    for i := 0; i < b.N; i++ {
+        p := NewParser(bytes.NewReader(data), options...)
+
+        for {
+            _, err := p.Next()
+            if err == io.EOF {
+                break
+            }
+
+            log.PanicIf(err)
+        }
+    }
+
+    return len(data)
+}
+
+func BenchmarkParser_Next_Decoder(b *testing.B) {
+    benchmarkNext(b, WithStandardDecoder())
+}
+
+func BenchmarkParser_Next_NativeScanner(b *testing.B) {
+    benchmarkNext(b, WithNativeScanner())
+}
+
+func BenchmarkParser_Next_Selectors(b *testing.B) {
+    selector, err := ParseSelector("$.locations[0]")
+    log.PanicIf(err)
+
+    // Everything but the first record is skipped without being tokenized.
+    size := benchmarkNext(b, WithSelectors(selector))
+    checkThroughput(b, size)
+}
+
+func BenchmarkParser_Parse(b *testing.B) {
+    data := benchmarkDocument()
+
+    b.SetBytes(int64(len(data)))
+    b.ReportAllocs()
+    b.ResetTimer()
+
+    for i := 0; i < b.N; i++ {
+        p := NewParser(bytes.NewReader(data))
+
+        c := make(chan interface{}, 100)
+
+        err := p.Parse(c)
+        log.PanicIf(err)
+
+        for range c {
+        }
+
+        log.PanicIf(p.Err())
+    }
+}
+
+func TestElements_NativeScanner(t *testing.T) {
+    r := strings.NewReader(`{"skipped": {"aa": [1, {"bb": "]"}]}, "items": [1, "two", 3]}`)
+
+    values := make([]int, 0)
+    failures := 0
+
+    for value, err := range Elements[int](r, "$.items", WithNativeScanner()) {
+        if err != nil {
+            failures++
+            continue
+        }
+
+        values = append(values, value)
+    }
+
+    if reflect.DeepEqual(values, []int{1, 3}) == false {
+        t.Fatalf("Values not correct: %v", values)
+    } else if failures != 1 {
+        t.Fatalf("Failure count not correct: (%d)", failures)
+    }
+}
@@ -0,0 +1,276 @@
+package jsonreader
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+
+    "github.com/dsoprea/go-logging"
+)
+
+// selectorStep is one step of a selector.
+type selectorStep struct {
+    // recursive indicates that the step can match at any depth below the
+    // previous step ("..").
+    recursive bool
+
+    // wildcard matches any key or index ("*").
+    wildcard bool
+
+    key string
+    index int
+    isIndex bool
+}
+
+func (ss selectorStep) matches(pe PathElement) bool {
+    if ss.wildcard == true {
+        return true
+    } else if ss.isIndex != pe.IsIndex {
+        return false
+    } else if ss.isIndex == true {
+        return ss.index == pe.Index
+    }
+
+    return ss.key == pe.Key
+}
+
+// Selector is a compiled path pattern. It's a subset of JSONPath: "$" followed
+// by any number of ".key", "['key']", "[index]", ".*", or "[*]" steps, where
+// any step may be prefixed with ".." to match at any depth (e.g.
+// "$.locations[*]" or "$..timestampMs").
+type Selector struct {
+    expression string
+    steps []selectorStep
+}
+
+// ParseSelector compiles a path pattern.
+func ParseSelector(expression string) (selector *Selector, err error) {
+    defer func() {
+        if state := recover(); state != nil {
+            err = fmt.Errorf("%w: %s", ErrInvalidSelector, unwrapError(state).Error())
+        }
+    }()
+
+    if strings.HasPrefix(expression, "$") == false {
+        log.Panicf("selector must start with '$': [%s]", expression)
+    }
+
+    steps := make([]selectorStep, 0)
+
+    s := expression[1:]
+    for len(s) > 0 {
+        step := selectorStep{}
+
+        if strings.HasPrefix(s, "..") == true {
+            step.recursive = true
+            s = s[2:]
+
+            // A bracket may immediately follow the recursive operator.
+            if strings.HasPrefix(s, "[") == false {
+                s = "." + s
+            }
+        }
+
+        if s[0] == '.' {
+            s = s[1:]
+
+            end := strings.IndexAny(s, ".[")
+            if end == -1 {
+                end = len(s)
+            }
+
+            name := s[:end]
+            s = s[end:]
+
+            if name == "" {
+                log.Panicf("selector has an empty key: [%s]", expression)
+            } else if name == "*" {
+                step.wildcard = true
+            } else {
+                step.key = name
+            }
+        } else if s[0] == '[' {
+            var err error
+
+            s, err = parseSelectorBracket(s[1:], &step)
+            if err != nil {
+                log.Panicf("selector has an invalid bracket (%s): [%s]", err.Error(), expression)
+            }
+        } else {
+            log.Panicf("selector has an unexpected character at [%s]: [%s]", s, expression)
+        }
+
+        steps = append(steps, step)
+    }
+
+    selector = &Selector{
+        expression: expression,
+        steps: steps,
+    }
+
+    return selector, nil
+}
+
+// parseSelectorBracket parses the content of a bracket (after the opening
+// bracket) into the given step and returns whatever follows the closing
+// bracket.
+func parseSelectorBracket(s string, step *selectorStep) (remaining string, err error) {
+    defer func() {
+        if state := recover(); state != nil {
+            err = unwrapError(state)
+        }
+    }()
+
+    if s == "" {
+        log.Panicf("bracket not closed")
+    }
+
+    if s[0] == '\'' || s[0] == '"' {
+        quote := s[0]
+        key := strings.Builder{}
+
+        i := 1
+        for ; i < len(s) && s[i] != quote; i++ {
+            if s[i] == '\\' && i + 1 < len(s) {
+                i++
+            }
+
+            key.WriteByte(s[i])
+        }
+
+        if i >= len(s) {
+            log.Panicf("quote not closed")
+        }
+
+        step.key = key.String()
+        s = s[i + 1:]
+    } else {
+        end := strings.IndexByte(s, ']')
+        if end == -1 {
+            log.Panicf("bracket not closed")
+        }
+
+        content := s[:end]
+        s = s[end:]
+
+        if content == "*" {
+            step.wildcard = true
+        } else {
+            index, err := strconv.Atoi(content)
+            if err != nil || index < 0 {
+                log.Panicf("index not valid: [%s]", content)
+            }
+
+            step.index = index
+            step.isIndex = true
+        }
+    }
+
+    if strings.HasPrefix(s, "]") == false {
+        log.Panicf("bracket not closed")
+    }
+
+    return s[1:], nil
+}
+
+// String returns the expression that the selector was compiled from.
+func (s *Selector) String() string {
+    return s.expression
+}
+
+// Match returns true if the path is at or under a path matched by the
+// selector.
+func (s *Selector) Match(path Path) bool {
+    states, matched := initialSelectorStates([]*Selector{s})
+    for _, pe := range path {
+        if matched == true {
+            return true
+        } else if len(states) == 0 {
+            return false
+        }
+
+        states, matched = advanceSelectors(states, pe)
+    }
+
+    return matched
+}
+
+// selectorState is a partial match of a selector: the steps before step have
+// been matched.
+type selectorState struct {
+    selector *Selector
+    step int
+}
+
+// initialSelectorStates returns the states for the root path.
+func initialSelectorStates(selectors []*Selector) (states []selectorState, matched bool) {
+    states = make([]selectorState, 0, len(selectors))
+    for _, selector := range selectors {
+        if len(selector.steps) == 0 {
+            matched = true
+        } else {
+            states = append(states, selectorState{selector: selector})
+        }
+    }
+
+    return states, matched
+}
+
+// advanceSelectors returns the states after descending from a path into the
+// given child. matched is true if any selector matches the child's path. If
+// nothing can match the child or anything under it, there will be no states.
+func advanceSelectors(states []selectorState, pe PathElement) (next []selectorState, matched bool) {
+    for _, ss := range states {
+        step := ss.selector.steps[ss.step]
+
+        // A recursive step can also match further down.
+        if step.recursive == true {
+            next = appendSelectorState(next, ss)
+        }
+
+        if step.matches(pe) == true {
+            if ss.step + 1 == len(ss.selector.steps) {
+                matched = true
+            } else {
+                next = appendSelectorState(next, selectorState{selector: ss.selector, step: ss.step + 1})
+            }
+        }
+    }
+
+    return next, matched
+}
+
+// matchSelectors returns true if any selector matches the child's path. It's
+// the same as advanceSelectors but doesn't allocate.
+func matchSelectors(states []selectorState, pe PathElement) bool {
+    for _, ss := range states {
+        if ss.step + 1 == len(ss.selector.steps) && ss.selector.steps[ss.step].matches(pe) == true {
+            return true
+        }
+    }
+
+    return false
+}
+
+// selectorsCanMatch returns true if any selector could match the child's path or
+// anything under it. It doesn't allocate.
+func selectorsCanMatch(states []selectorState, pe PathElement) bool {
+    for _, ss := range states {
+        step := ss.selector.steps[ss.step]
+        if step.recursive == true || step.matches(pe) == true {
+            return true
+        }
+    }
+
+    return false
+}
+
+func appendSelectorState(states []selectorState, ss selectorState) []selectorState {
+    for _, existing := range states {
+        if existing == ss {
+            return states
+        }
+    }
+
+    return append(states, ss)
+}
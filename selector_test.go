@@ -0,0 +1,143 @@
+package jsonreader
+
+import (
+    "testing"
+    "os"
+    "path"
+    "strings"
+
+    "github.com/dsoprea/go-logging"
+)
+
+func mustParseSelector(expression string) *Selector {
+    selector, err := ParseSelector(expression)
+    log.PanicIf(err)
+
+    return selector
+}
+
+func TestParseSelector(t *testing.T) {
+    selector := mustParseSelector(`$.locations[*]..activity[2]['a.b']["c"].*`)
+
+    expected := []selectorStep{
+        selectorStep{key: "locations"},
+        selectorStep{wildcard: true},
+        selectorStep{recursive: true, key: "activity"},
+        selectorStep{index: 2, isIndex: true},
+        selectorStep{key: "a.b"},
+        selectorStep{key: "c"},
+        selectorStep{wildcard: true},
+    }
+
+    if len(selector.steps) != len(expected) {
+        t.Fatalf("Step count not correct: %v", selector.steps)
+    }
+
+    for i, step := range selector.steps {
+        if step != expected[i] {
+            t.Fatalf("Step (%d) not correct: %v != %v", i, step, expected[i])
+        }
+    }
+}
+
+func TestParseSelector_Invalid(t *testing.T) {
+    expressions := []string{
+        "locations",
+        "$.",
+        "$[",
+        "$[abc]",
+        "$[-1]",
+        "$['abc]",
+        "$x",
+    }
+
+    for _, expression := range expressions {
+        if _, err := ParseSelector(expression); err == nil {
+            t.Fatalf("Expected error for [%s].", expression)
+        }
+    }
+}
+
+func TestSelector_Match(t *testing.T) {
+    p := Path{
+        PathElement{Key: "locations"},
+        PathElement{Index: 7, IsIndex: true},
+        PathElement{Key: "timestampMs"},
+    }
+
+    matches := map[string]bool {
+        "$": true,
+        "$.locations": true,
+        "$.locations[*]": true,
+        "$.locations[7]": true,
+        "$.locations[6]": false,
+        "$..timestampMs": true,
+        "$..accuracy": false,
+        "$.*.*.timestampMs": true,
+        "$.locations[7].timestampMs.extra": false,
+    }
+
+    for expression, expected := range matches {
+        if mustParseSelector(expression).Match(p) != expected {
+            t.Fatalf("Match for [%s] should be (%v).", expression, expected)
+        }
+    }
+}
+
+func TestParser_Selectors(t *testing.T) {
+    r := strings.NewReader(`{"aa": [1, {"bb": "cc"}], "dd": {"bb": "ee"}}`)
+
+    p := NewParser(r, WithSelectors(mustParseSelector("$.aa[*]"), mustParseSelector("$..bb")))
+
+    actual := make([]string, 0)
+    for token, err := range p.All() {
+        log.PanicIf(err)
+
+        actual = append(actual, flattenToken(token) + " " + p.Path().String())
+    }
+
+    expected := []string{
+        "#FLOAT64=1.000000 $.aa[0]",
+        "/OBJECTOPEN $.aa[1]",
+        ":bb $.aa[1].bb",
+        "[bb] S cc $.aa[1].bb",
+        "/OBJECTCLOSE $.aa[1]",
+        "@bb:cc $.aa[1]",
+        ":bb $.dd.bb",
+        "[bb] S ee $.dd.bb",
+    }
+
+    if len(actual) != len(expected) {
+        t.Fatalf("Token count not correct: %v", actual)
+    }
+
+    for i, entry := range actual {
+        if expected[i] != entry {
+            t.Fatalf("Item (%d) value [%s] should be [%s].", i, entry, expected[i])
+        }
+    }
+}
+
+func TestParser_Selectors_Data1(t *testing.T) {
+    filepath := path.Join(testingAssetsPath, "data1.json")
+
+    f, err := os.Open(filepath)
+    log.PanicIf(err)
+
+    defer f.Close()
+
+    p := NewParser(f, WithSelectors(mustParseSelector("$.locations[*]")))
+
+    count := 0
+    for token, err := range p.All() {
+        log.PanicIf(err)
+
+        if token.Kind() == KindSimpleObject && len(p.Path()) == 2 {
+            count++
+        }
+    }
+
+    if count != 12 {
+        t.Fatalf("Location count not correct: (%d)", count)
+    }
+}
@@ -0,0 +1,66 @@
+package jsonreader
+
+// SimpleList is produced after the ListClose of every list that wasn't skipped
+// when WithSimpleLists is given. It has the scalar elements of the list in
+// order (nested objects and lists are left out).
+type SimpleList []interface{}
+
+// WithSimpleLists produces a SimpleList after the ListClose of every list.
+// Note that the scalar elements of a list are held in memory until the list
+// closes.
+func WithSimpleLists() ParserOption {
+    return func(p *Parser) {
+        p.simpleLists = true
+    }
+}
+
+// WithSimpleListsInObjects also sets the lists that only have scalar elements
+// (e.g. `"tags": ["a", "b"]`) into the SimpleObject of the object that they're
+// in, as SimpleLists. This doesn't produce SimpleList tokens unless
+// WithSimpleLists is also given.
+func WithSimpleListsInObjects() ParserOption {
+    return func(p *Parser) {
+        p.simpleListsInObjects = true
+    }
+}
+
+// startSimpleList sets up the frame of a list that is opening to collect its
+// scalar elements. The parent is the frame that the list is in.
+func (p *Parser) startSimpleList(parent, frame *parseFrame) {
+    if p.simpleLists == false && p.simpleListsInObjects == false {
+        return
+    }
+
+    // A list that has a container in it isn't a scalar list anymore.
+    if parent.simpleList != nil {
+        parent.hasContainer = true
+    }
+
+    if frame.dc.Delimiter() == '[' {
+        frame.simpleList = make(SimpleList, 0)
+    }
+}
+
+// collectSimpleList adds a scalar to the list that we're in, if we're
+// collecting its elements.
+func (p *Parser) collectSimpleList(current *parseFrame, value interface{}) {
+    if current.simpleList != nil {
+        current.simpleList = append(current.simpleList, value)
+    }
+}
+
+// finishSimpleList produces the SimpleList of a list that closed and sets it
+// into the SimpleObject of the parent, if requested.
+func (p *Parser) finishSimpleList(last, parent *parseFrame) {
+    if last.simpleList == nil || last.skipped == true {
+        return
+    }
+
+    if p.simpleLists == true {
+        p.emit(last.simpleList)
+    }
+
+    if p.simpleListsInObjects == true && last.hasContainer == false && parent.dc.Delimiter() == '{' {
+        parent.setSimple(parent.previousKey, last.simpleList)
+    }
+}
@@ -0,0 +1,102 @@
+package jsonreader
+
+import (
+    "testing"
+    "strings"
+    "fmt"
+
+    "github.com/dsoprea/go-logging"
+)
+
+func TestParser_SimpleLists(t *testing.T) {
+    r := strings.NewReader(`{"tags": ["a", "b"], "points": [[1, 2], [3, null]], "mixed": [true, {"aa": 1}, "x"]}`)
+
+    p := NewParser(r, WithSimpleLists())
+
+    actual := make([]string, 0)
+    for token, err := range p.All() {
+        log.PanicIf(err)
+
+        if token.Kind() == KindSimpleList {
+            span := token.Span()
+            actual = append(actual, fmt.Sprintf("%s %s (%d-%d)", flattenToken(token), token.Path(), span.Start.Offset, span.End.Offset))
+        }
+    }
+
+    expected := []string {
+        "@LIST=[a b] $.tags (9-19)",
+        "@LIST=[1 2] $.points[0] (32-38)",
+        "@LIST=[3 <nil>] $.points[1] (40-49)",
+        "@LIST=[] $.points (31-50)",
+        "@LIST=[true x] $.mixed (61-83)",
+    }
+
+    if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+        t.Fatalf("Lists not correct:\n%s", strings.Join(actual, "\n"))
+    }
+}
+
+func TestParser_SimpleLists_Disabled(t *testing.T) {
+    r := strings.NewReader(`{"tags": ["a", "b"]}`)
+
+    p := NewParser(r)
+
+    for token, err := range p.All() {
+        log.PanicIf(err)
+
+        if token.Kind() == KindSimpleList {
+            t.Fatalf("No SimpleList expected.")
+        } else if token.Kind() == KindSimpleObject && len(token.Value().(SimpleObject)) != 0 {
+            t.Fatalf("Expected an empty SimpleObject: %v", token.Value())
+        }
+    }
+}
+
+func TestParser_SimpleListsInObjects(t *testing.T) {
+    r := strings.NewReader(`{"aa": 1, "tags": ["a", "b"], "points": [[1, 2]], "empty": [], "nested": {"cc": [3]}}`)
+
+    p := NewParser(r, WithSimpleListsInObjects(), WithNumberMode(NumberModeExact))
+
+    objects := make([]string, 0)
+    for token, err := range p.All() {
+        log.PanicIf(err)
+
+        if token.Kind() == KindSimpleList {
+            t.Fatalf("No SimpleList tokens expected.")
+        } else if token.Kind() == KindSimpleObject {
+            objects = append(objects, flattenToken(token))
+        }
+    }
+
+    // Only lists of scalars are included.
+    expected := []string {
+        "@cc:[3]",
+        "@aa:1 empty:[] tags:[a b]",
+    }
+
+    if strings.Join(objects, "\n") != strings.Join(expected, "\n") {
+        t.Fatalf("Objects not correct:\n%s", strings.Join(objects, "\n"))
+    }
+}
+
+func TestParser_SimpleLists_Skipped(t *testing.T) {
+    r := strings.NewReader(`[[1, 2], [3, 4]]`)
+
+    p := NewParser(r, WithSimpleLists())
+
+    lists := make([]string, 0)
+    for token, err := range p.All() {
+        log.PanicIf(err)
+
+        if token.Kind() == KindListOpen && token.Path().String() == "$[0]" {
+            err := p.Skip()
+            log.PanicIf(err)
+        } else if token.Kind() == KindSimpleList {
+            lists = append(lists, flattenToken(token))
+        }
+    }
+
+    if strings.Join(lists, " ") != "@LIST=[3 4] @LIST=[]" {
+        t.Fatalf("Lists not correct: %v", lists)
+    }
+}
@@ -0,0 +1,133 @@
+package jsonreader
+
+import (
+    "github.com/dsoprea/go-logging"
+)
+
+// discardValue consumes a value without building anything from it. The
+// decoder still scans the value to find its end, but doesn't produce tokens for
+// anything inside of it.
+type discardValue struct{}
+
+func (discardValue) UnmarshalJSON(data []byte) error {
+    return nil
+}
+
+// skipValue consumes the next value (which may be a whole object or list)
+// without producing any tokens for it.
+func (p *Parser) skipValue() (err error) {
+    defer func() {
+        if state := recover(); state != nil {
+            err = unwrapError(state)
+        }
+    }()
+
+    err = p.source.Skip()
+    if err != nil {
+        log.Panic(p.sourceError(err))
+    }
+
+    p.currentFrame().i++
+
+    return nil
+}
+
+// Skip discards the remainder of the innermost object or list that is
+// currently open (e.g. right after Next() returns an ObjectOpen or ListOpen
+// that you're not interested in). The next token will be its ObjectClose or
+// ListClose, and no SimpleObject is produced for a skipped object. The skipped
+// values are only checked for balanced brackets and quotes, and nothing beyond
+// what's being read is buffered (unless WithStandardDecoder was given). If the
+// object or list is part of an object being materialized, it's left out of the
+// Object (the key, or the element of a list, is dropped).
+func (p *Parser) Skip() (err error) {
+    if p.err != nil {
+        return p.err
+    } else if len(p.frames) <= 1 {
+        return ErrNotInContainer
+    }
+
+    defer func() {
+        if state := recover(); state != nil {
+            err = unwrapError(state)
+
+            // We can't tell where we are anymore.
+            p.err = err
+        }
+    }()
+
+    current := p.currentFrame()
+    current.skipped = true
+
+    isInObject := current.dc.Delimiter() == '{'
+
+    for {
+        // If we've read a key, we still need to skip its value.
+        if isInObject == true && current.i % 2 == 1 {
+            err := p.skipValue()
+            log.PanicIf(err)
+
+            continue
+        }
+
+        if p.source.More() == false {
+            break
+        }
+
+        if isInObject == true {
+            _, err := p.source.Token()
+            if err != nil {
+                log.Panic(p.sourceError(err))
+            }
+
+            current.i++
+        } else {
+            err := p.skipValue()
+            log.PanicIf(err)
+        }
+    }
+
+    return nil
+}
+
+// skipUnselected skips the next value if nothing in it can be matched by a
+// selector. This is how we avoid tokenizing large subtrees that nobody is
+// interested in.
+func (p *Parser) skipUnselected() (skipped bool, err error) {
+    defer func() {
+        if state := recover(); state != nil {
+            err = unwrapError(state)
+        }
+    }()
+
+    current := p.currentFrame()
+    if current.selected == true {
+        return false, nil
+    }
+
+    switch current.dc.Delimiter() {
+    case '{':
+        // We're only able to skip once we have the key.
+        if current.i % 2 == 0 {
+            return false, nil
+        }
+    case '[':
+        if p.source.More() == false {
+            return false, nil
+        }
+    default:
+        // We're at the top level, which always has the same path as the
+        // root.
+        return false, nil
+    }
+
+    element, _ := current.cursor()
+    if selectorsCanMatch(current.selectorStates, element) == true {
+        return false, nil
+    }
+
+    err = p.skipValue()
+    log.PanicIf(err)
+
+    return true, nil
+}
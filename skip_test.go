@@ -0,0 +1,155 @@
+package jsonreader
+
+import (
+    "testing"
+    "strings"
+    "bytes"
+    "runtime"
+
+    "github.com/dsoprea/go-logging"
+)
+
+func TestParser_Skip(t *testing.T) {
+    r := strings.NewReader(`[{"aa": 1, "bb": {"cc": [2, 3]}}, [4, [5], 6], 7]`)
+
+    p := NewParser(r)
+
+    actual := make([]string, 0)
+    for token, err := range p.All() {
+        log.PanicIf(err)
+
+        actual = append(actual, flattenToken(token) + " " + p.Path().String())
+
+        switch token.Kind() {
+        case KindObjectKey:
+            if token.Value().(ObjectKey) == "aa" {
+                // Skip after a key and before its value.
+                err := p.Skip()
+                log.PanicIf(err)
+            }
+        case KindListOpen:
+            if p.Path().String() == "$[1]" {
+                err := p.Skip()
+                log.PanicIf(err)
+            }
+        }
+    }
+
+    expected := []string{
+        "/LISTOPEN $",
+        "/OBJECTOPEN $[0]",
+        ":aa $[0].aa",
+        "/OBJECTCLOSE $[0]",
+        "/LISTOPEN $[1]",
+        "/LISTCLOSE $[1]",
+        "#FLOAT64=7.000000 $[2]",
+        "/LISTCLOSE $",
+    }
+
+    if len(actual) != len(expected) {
+        t.Fatalf("Token count not correct: %v", actual)
+    }
+
+    for i, entry := range actual {
+        if expected[i] != entry {
+            t.Fatalf("Item (%d) value [%s] should be [%s].", i, entry, expected[i])
+        }
+    }
+}
+
+func TestParser_Skip_TopLevel(t *testing.T) {
+    r := strings.NewReader(`[1]`)
+
+    p := NewParser(r)
+
+    err := p.Skip()
+    if err == nil {
+        t.Fatalf("Expected error when not in a container.")
+    }
+}
+
+func TestParser_Skip_SyntaxError(t *testing.T) {
+    r := strings.NewReader(`[{"aa": 1, ]`)
+
+    p := NewParser(r)
+
+    _, err := p.Next()
+    log.PanicIf(err)
+
+    err = p.Skip()
+    if err == nil {
+        t.Fatalf("Expected error for invalid syntax.")
+    }
+}
+
+func TestParser_Selectors_SkipUnselected(t *testing.T) {
+    r := strings.NewReader(`{"xx": {"big": [1, 2, {"a": 3}]}, "aa": [{"a": 4}, 5], "yy": [6]}`)
+
+    p := NewParser(r, WithSelectors(mustParseSelector("$.aa[0]")))
+
+    actual := make([]string, 0)
+    for token, err := range p.All() {
+        log.PanicIf(err)
+
+        actual = append(actual, flattenToken(token) + " " + p.Path().String())
+    }
+
+    expected := []string{
+        "/OBJECTOPEN $.aa[0]",
+        ":a $.aa[0].a",
+        "[a] F 4.000000 $.aa[0].a",
+        "/OBJECTCLOSE $.aa[0]",
+        "@a:4 $.aa[0]",
+    }
+
+    if len(actual) != len(expected) {
+        t.Fatalf("Token count not correct: %v", actual)
+    }
+
+    for i, entry := range actual {
+        if expected[i] != entry {
+            t.Fatalf("Item (%d) value [%s] should be [%s].", i, entry, expected[i])
+        }
+    }
+}
+
+func TestParser_Selectors_SkipUnselected_Memory(t *testing.T) {
+    b := new(bytes.Buffer)
+    b.WriteString(`{"big": [`)
+
+    for i := 0; i < 200000; i++ {
+        if i > 0 {
+            b.WriteString(`,`)
+        }
+
+        b.WriteString(`{"key": "some value", "list": [1, 2, 3]}`)
+    }
+
+    b.WriteString(`], "aa": 1}`)
+
+    data := b.Bytes()
+
+    var before, after runtime.MemStats
+    runtime.ReadMemStats(&before)
+
+    p := NewParser(bytes.NewReader(data), WithSelectors(mustParseSelector("$.aa")))
+
+    found := false
+    for token, err := range p.All() {
+        log.PanicIf(err)
+
+        if token.Kind() == KindObjectValue {
+            found = true
+        }
+    }
+
+    runtime.ReadMemStats(&after)
+
+    // The skipped list is much larger than what we allow to be allocated.
+    allocated := after.TotalAlloc - before.TotalAlloc
+    if found == false {
+        t.Fatalf("Expected the selected value.")
+    } else if allocated > uint64(len(data) / 8) {
+        t.Fatalf("The skipped value was buffered: (%d) bytes allocated for (%d) bytes of input", allocated, len(data))
+    }
+}
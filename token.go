@@ -0,0 +1,245 @@
+package jsonreader
+
+import (
+    "strconv"
+)
+
+// Kind identifies what a Token is.
+type Kind int
+
+const (
+    // KindInvalid is the kind of the empty Token that is returned along with
+    // an error.
+    KindInvalid Kind = iota
+
+    // KindObjectOpen is the start of an object. Value() is an ObjectOpen.
+    KindObjectOpen
+
+    // KindObjectClose is the end of an object. Value() is an ObjectClose.
+    KindObjectClose
+
+    // KindListOpen is the start of a list. Value() is a ListOpen.
+    KindListOpen
+
+    // KindListClose is the end of a list. Value() is a ListClose.
+    KindListClose
+
+    // KindObjectKey is a key of an object. Value() is an ObjectKey.
+    KindObjectKey
+
+    // KindObjectValue is the scalar value of an object key. Value() is an
+    // ObjectValue.
+    KindObjectValue
+
+    // KindValue is a bool, number, or string that isn't the value of an
+    // object key. Value() is the bool, number, or string.
+    KindValue
+
+    // KindNull is a null that isn't the value of an object key. Value() is a
+    // Null.
+    KindNull
+
+    // KindSimpleObject follows the end of every object that wasn't skipped.
+    // Value() is a SimpleObject (or an OrderedSimpleObject if
+    // WithOrderedObjects was given).
+    KindSimpleObject
+
+    // KindSimpleList follows the end of every list that wasn't skipped if
+    // WithSimpleLists was given. Value() is a SimpleList.
+    KindSimpleList
+
+    // KindObject follows the SimpleObject of a materialized object. Value()
+    // is an Object (or an OrderedObject if WithOrderedObjects was given).
+    KindObject
+
+    // KindDocumentStart is the start of a document. Value() is a
+    // DocumentStart.
+    KindDocumentStart
+
+    // KindDocumentEnd is the end of a document. Value() is a DocumentEnd.
+    KindDocumentEnd
+
+    // KindRecordError is a document that is invalid. Value() is a
+    // RecordError.
+    KindRecordError
+)
+
+// String returns a descriptive name for the kind.
+func (k Kind) String() string {
+    switch k {
+    case KindInvalid:
+        return "Invalid"
+    case KindObjectOpen:
+        return "ObjectOpen"
+    case KindObjectClose:
+        return "ObjectClose"
+    case KindListOpen:
+        return "ListOpen"
+    case KindListClose:
+        return "ListClose"
+    case KindObjectKey:
+        return "ObjectKey"
+    case KindObjectValue:
+        return "ObjectValue"
+    case KindValue:
+        return "Value"
+    case KindNull:
+        return "Null"
+    case KindSimpleObject:
+        return "SimpleObject"
+    case KindSimpleList:
+        return "SimpleList"
+    case KindObject:
+        return "Object"
+    case KindDocumentStart:
+        return "DocumentStart"
+    case KindDocumentEnd:
+        return "DocumentEnd"
+    case KindRecordError:
+        return "RecordError"
+    }
+
+    return "Kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Token is what Next() and All() produce. Every token has its kind and where
+// it was found. Tokens can only be created by this package. This is a value
+// rather than an interface so that producing one doesn't allocate.
+type Token struct {
+    kind Kind
+    value interface{}
+    location tokenLocation
+}
+
+// ContainerInfo describes the object or list of an ObjectOpen, ObjectClose,
+// ListOpen, or ListClose token.
+type ContainerInfo struct {
+    // Depth is the depth of the object or list (zero for the root).
+    Depth int
+
+    // Parent is '{' if the object or list is the value of a key of another
+    // object, '[' if it's an element of another list, and zero if it's the
+    // root.
+    Parent rune
+
+    // Key is the key of the object or list in its parent object.
+    Key string
+
+    // Index is the index of the object or list in its parent list.
+    Index int
+
+    // Children is the number of members of the object or elements of the
+    // list, including any that were skipped. It's only set for ObjectClose
+    // and ListClose.
+    Children int
+}
+
+// kindOf returns the kind of a token as it's sent by Parse().
+func kindOf(value interface{}) Kind {
+    switch value.(type) {
+    case ObjectOpen:
+        return KindObjectOpen
+    case ObjectClose:
+        return KindObjectClose
+    case ListOpen:
+        return KindListOpen
+    case ListClose:
+        return KindListClose
+    case ObjectKey:
+        return KindObjectKey
+    case ObjectValue:
+        return KindObjectValue
+    case Null:
+        return KindNull
+    case SimpleObject, OrderedSimpleObject:
+        return KindSimpleObject
+    case SimpleList:
+        return KindSimpleList
+    case Object, OrderedObject:
+        return KindObject
+    case DocumentStart:
+        return KindDocumentStart
+    case DocumentEnd:
+        return KindDocumentEnd
+    case RecordError:
+        return KindRecordError
+    }
+
+    return KindValue
+}
+
+// Kind returns what the token is.
+func (t Token) Kind() Kind {
+    return t.kind
+}
+
+// Depth returns how many objects and lists the token is inside of. The
+// ObjectOpen, ObjectClose, SimpleObject, etc. of an object have the depth of
+// the object itself (zero for the root).
+func (t Token) Depth() int {
+    return t.location.dc.Depth()
+}
+
+// Path returns the path of the token (see Parser.Path).
+func (t Token) Path() Path {
+    return t.location.Path()
+}
+
+// Offset returns the byte offset in the input that the token starts at.
+func (t Token) Offset() int64 {
+    return t.location.span.Start.Offset
+}
+
+// Span returns where the token starts and ends in the input (see
+// Parser.Span).
+func (t Token) Span() Span {
+    return t.location.span
+}
+
+// Container returns the depth and parent of an object or list and, for
+// ObjectClose and ListClose, how many children it had. It returns false for
+// any other kind of token.
+func (t Token) Container() (info ContainerInfo, ok bool) {
+    switch t.kind {
+    case KindObjectOpen, KindObjectClose, KindListOpen, KindListClose:
+    default:
+        return ContainerInfo{}, false
+    }
+
+    // Containers are located in their parent, so the element is their key or
+    // index there.
+    info = ContainerInfo{
+        Depth: t.location.dc.Depth(),
+        Parent: t.location.dc.Delimiter(),
+        Children: t.location.children,
+    }
+
+    if t.location.hasElement == true {
+        if t.location.element.IsIndex == true {
+            info.Index = t.location.element.Index
+        } else {
+            info.Key = t.location.element.Key
+        }
+    }
+
+    return info, true
+}
+
+// Value returns the token as it is sent by Parse(): an ObjectOpen,
+// ObjectClose, ListOpen, ListClose, ObjectKey, ObjectValue, SimpleObject,
+// OrderedSimpleObject, SimpleList, Object, OrderedObject, Null, DocumentStart,
+// DocumentEnd, RecordError, or a bool, number, or string (see NumberMode for
+// the types that numbers can be produced as).
+func (t Token) Value() interface{} {
+    switch t.kind {
+    case KindObjectKey:
+        return ObjectKey(t.location.element.Key)
+    case KindObjectValue:
+        return ObjectValue{
+            key: t.location.element.Key,
+            value: t.value,
+        }
+    }
+
+    return t.value
+}
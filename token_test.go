@@ -0,0 +1,176 @@
+package jsonreader
+
+import (
+    "testing"
+    "strings"
+    "fmt"
+    "io"
+
+    "github.com/dsoprea/go-logging"
+)
+
+func TestToken_Metadata(t *testing.T) {
+    r := strings.NewReader(`{"aa": [1, {"bb": null}], "cc": "x"}`)
+
+    p := NewParser(r)
+
+    actual := make([]string, 0)
+    for token, err := range p.All() {
+        log.PanicIf(err)
+
+        actual = append(actual, fmt.Sprintf("%s %d %s %d", token.Kind(), token.Depth(), token.Path(), token.Offset()))
+    }
+
+    expected := []string {
+        "ObjectOpen 0 $ 0",
+        "ObjectKey 1 $.aa 1",
+        "ListOpen 1 $.aa 7",
+        "Value 2 $.aa[0] 8",
+        "ObjectOpen 2 $.aa[1] 11",
+        "ObjectKey 3 $.aa[1].bb 12",
+        "ObjectValue 3 $.aa[1].bb 18",
+        "ObjectClose 2 $.aa[1] 22",
+        "SimpleObject 2 $.aa[1] 11",
+        "ListClose 1 $.aa 23",
+        "ObjectKey 1 $.cc 26",
+        "ObjectValue 1 $.cc 32",
+        "ObjectClose 0 $ 35",
+        "SimpleObject 0 $ 0",
+    }
+
+    if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+        t.Fatalf("Tokens not correct:\n%s", strings.Join(actual, "\n"))
+    }
+}
+
+func TestToken_Value(t *testing.T) {
+    r := strings.NewReader(`[true, null, "x"]`)
+
+    p := NewParser(r)
+
+    values := make([]string, 0)
+    for token, err := range p.All() {
+        log.PanicIf(err)
+
+        values = append(values, fmt.Sprintf("%T", token.Value()))
+    }
+
+    if strings.Join(values, " ") != "jsonreader.ListOpen bool jsonreader.Null string jsonreader.ListClose" {
+        t.Fatalf("Values not correct: %v", values)
+    }
+}
+
+func TestToken_Channel(t *testing.T) {
+    r := strings.NewReader(`{"aa": 1}`)
+
+    p := NewParser(r)
+
+    ts, err := p.ParseToTokenSlice(r)
+    log.PanicIf(err)
+
+    // The channel still carries the values themselves.
+    if _, ok := ts[0].(ObjectOpen); ok == false {
+        t.Fatalf("Expected an ObjectOpen: %v", ts[0])
+    } else if _, ok := ts[0].(Token); ok == true {
+        t.Fatalf("Expected the value rather than a Token.")
+    }
+}
+
+func TestToken_Error(t *testing.T) {
+    p := NewParser(strings.NewReader(`[1`))
+
+    var token Token
+    var err error
+
+    for {
+        token, err = p.Next()
+        if err != nil {
+            break
+        }
+    }
+
+    if err == io.EOF {
+        t.Fatalf("Expected a syntax error.")
+    } else if token.Kind() != KindInvalid || token.Value() != nil {
+        t.Fatalf("Expected an empty token: %v", token)
+    }
+}
+
+func TestKind_String(t *testing.T) {
+    if KindSimpleObject.String() != "SimpleObject" {
+        t.Fatalf("String not correct: [%s]", KindSimpleObject)
+    } else if Kind(99).String() != "Kind(99)" {
+        t.Fatalf("String not correct for unknown kind: [%s]", Kind(99))
+    }
+}
+
+func TestToken_Container(t *testing.T) {
+    r := strings.NewReader(`{"aa": [1, {"bb": null, "cc": 2}, []], "dd": {}}`)
+
+    p := NewParser(r)
+
+    actual := make([]string, 0)
+    for token, err := range p.All() {
+        log.PanicIf(err)
+
+        info, ok := token.Container()
+        if ok == false {
+            continue
+        }
+
+        parent := "-"
+        if info.Parent != 0 {
+            parent = string(info.Parent)
+        }
+
+        actual = append(actual, fmt.Sprintf("%s depth=%d parent=%s key=[%s] index=%d children=%d", token.Kind(), info.Depth, parent, info.Key, info.Index, info.Children))
+    }
+
+    expected := []string {
+        "ObjectOpen depth=0 parent=- key=[] index=0 children=0",
+        "ListOpen depth=1 parent={ key=[aa] index=0 children=0",
+        "ObjectOpen depth=2 parent=[ key=[] index=1 children=0",
+        "ObjectClose depth=2 parent=[ key=[] index=1 children=2",
+        "ListOpen depth=2 parent=[ key=[] index=2 children=0",
+        "ListClose depth=2 parent=[ key=[] index=2 children=0",
+        "ListClose depth=1 parent={ key=[aa] index=0 children=3",
+        "ObjectOpen depth=1 parent={ key=[dd] index=0 children=0",
+        "ObjectClose depth=1 parent={ key=[dd] index=0 children=0",
+        "ObjectClose depth=0 parent=- key=[] index=0 children=2",
+    }
+
+    if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+        t.Fatalf("Containers not correct:\n%s", strings.Join(actual, "\n"))
+    }
+}
+
+func TestToken_Container_Skipped(t *testing.T) {
+    r := strings.NewReader(`[[1, 2, [3]], 4]`)
+
+    p := NewParser(r)
+
+    children := make([]int, 0)
+    for token, err := range p.All() {
+        log.PanicIf(err)
+
+        if token.Kind() == KindListOpen && token.Depth() == 1 {
+            err := p.Skip()
+            log.PanicIf(err)
+        } else if token.Kind() == KindListClose {
+            info, _ := token.Container()
+            children = append(children, info.Children)
+        }
+    }
+
+    if fmt.Sprintf("%v", children) != "[3 2]" {
+        t.Fatalf("Children not correct: %v", children)
+    }
+
+    // Anything else isn't a container.
+    token, err := NewParser(strings.NewReader(`1`)).Next()
+    log.PanicIf(err)
+
+    if _, ok := token.Container(); ok == true {
+        t.Fatalf("Expected no container for a value.")
+    }
+}